@@ -2,7 +2,7 @@ import csv
 import os
 import re
 from os import path
-from typing import Pattern
+from typing import List, Pattern
 
 # Configuration constants
 METHODS = ["Chain", "Cuckoo", "Double", "Hopscotch", "Robin"]
@@ -80,7 +80,8 @@ def parse_insert_to_csv(method: str, key_kind: str, load_factor: str) -> None:
                 int(match.group('BytesPerOp'))
             ]
 
-        parse_benchmark_results(input_file_path, output_file_path, pattern, extract_row)
+        parse_benchmark_results(input_file_path, output_file_path, pattern, extract_row,
+                                header=["size", "ns_per_insert", "bytes_per_op"])
 
 
 def parse_get_to_csv(method: str, key_kind: str, load_factor: str) -> None:
@@ -108,7 +109,8 @@ def parse_get_to_csv(method: str, key_kind: str, load_factor: str) -> None:
                 float(match.group('NsPerOp'))
             ]
 
-        parse_benchmark_results(input_file_path, output_file_path, pattern, extract_row)
+        parse_benchmark_results(input_file_path, output_file_path, pattern, extract_row,
+                                header=["size", "ns_per_op"])
 
 
 def parse_delete_to_csv(method: str, key_kind: str, load_factor: str) -> None:
@@ -136,7 +138,8 @@ def parse_delete_to_csv(method: str, key_kind: str, load_factor: str) -> None:
             float(match.group('NsPerOp'))
         ]
 
-    parse_benchmark_results(input_file_path, output_file_path, pattern, extract_row)
+    parse_benchmark_results(input_file_path, output_file_path, pattern, extract_row,
+                            header=["size", "ns_per_op"])
 
 
 def parse_collisions(method: str, key_kind: str, load_factor: str) -> None:
@@ -181,7 +184,8 @@ def parse_benchmark_results(
     input_file_path: str,
     output_file_path: str,
     pattern: Pattern,
-    extract_row: callable
+    extract_row: callable,
+    header: List[str]
 ) -> None:
     """
     Parse benchmark results using regex pattern and save to CSV.
@@ -191,13 +195,15 @@ def parse_benchmark_results(
         output_file_path: Path to output file
         pattern: Regex pattern for matching lines
         extract_row: Function to extract row data from regex match
+        header: Column names written as the first row, like the CSVs of the Go harness
     """
     ensure_output_dir(output_file_path)
     
     with open(input_file_path, "r") as input_file, \
          open(output_file_path, 'w', newline='') as output_file:
         writer = csv.writer(output_file)
-        
+        writer.writerow(header)
+
         for line in input_file:
             if match := pattern.search(line):
                 row = extract_row(match)
@@ -184,15 +184,15 @@ def make_graphic(
         for i, method in enumerate(methods):
             if method not in split_methods:
                 file_path = path.join(input_dir, method, f'{file_suffix}.csv')
-                df = pd.read_csv(file_path, header=None)
-                ax1.plot(df[data_indexes[0]], df[data_indexes[1]],
+                df = pd.read_csv(file_path, header=0)
+                ax1.plot(df.iloc[:, data_indexes[0]], df.iloc[:, data_indexes[1]],
                          label=METHODS_FULL_NAME[method], color=colors[i], linewidth=3)
 
         # Plot split methods
         for method in split_methods:
             file_path = path.join(input_dir, method, f'{file_suffix}.csv')
-            df = pd.read_csv(file_path, header=None)
-            ax2.plot(df[data_indexes[0]], df[data_indexes[1]],
+            df = pd.read_csv(file_path, header=0)
+            ax2.plot(df.iloc[:, data_indexes[0]], df.iloc[:, data_indexes[1]],
                      label=METHODS_FULL_NAME[method], color=colors[methods.index(method)], linewidth=3)
 
         # Configure subplots
@@ -213,8 +213,8 @@ def make_graphic(
 
         for i, method in enumerate(methods):
             file_path = path.join(input_dir, method, f'{file_suffix}.csv')
-            df = pd.read_csv(file_path, header=None)
-            plt.plot(df[data_indexes[0]], df[data_indexes[1]],
+            df = pd.read_csv(file_path, header=0)
+            plt.plot(df.iloc[:, data_indexes[0]], df.iloc[:, data_indexes[1]],
                      label=METHODS_FULL_NAME[method], color=colors[i], linewidth=3)
 
         plt.xlabel(x_label, fontsize=18)
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"analyze/internal/results"
+	"analyze/internal/stats"
+	"cmp"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// cellKey matches the same measurement in two result sets.
+type cellKey struct {
+	experiment string
+	metric     string
+	variant    string
+	keyKind    string
+	loadFactor float64
+	size       int
+	labels     string
+}
+
+func (k cellKey) String() string {
+	name := fmt.Sprintf("%s/%s-%s-%.2f-%d", k.experiment, k.variant, k.keyKind, k.loadFactor, k.size)
+	if k.labels != "" {
+		name += "/" + k.labels
+	}
+
+	return name
+}
+
+func keyOf(r results.Record) cellKey {
+	labels := make([]string, 0, len(r.Labels))
+	for _, label := range slices.Sorted(maps.Keys(r.Labels)) {
+		labels = append(labels, label+"="+r.Labels[label])
+	}
+
+	return cellKey{r.Experiment, r.Metric, seriesName(r), r.KeyKind, r.LoadFactor, r.Size, strings.Join(labels, ",")}
+}
+
+type comparison struct {
+	key      cellKey
+	old, new stats.Summary
+	delta    float64
+	p        float64
+	// significant is false as well when there are too few repetitions to
+	// tell.
+	significant bool
+	// tested is false when a side has fewer than minRepetitions values.
+	tested bool
+}
+
+// minRepetitions is the fewest repetitions per side the Mann-Whitney test
+// runs on: with fewer, even two sides that do not overlap at all give an
+// exact p-value of 2/C(2n, n), which is not below alpha. Cells with fewer
+// are only held against the threshold.
+func minRepetitions(alpha float64) int {
+	n, orderings := 1, 2.0
+	for 2/orderings >= alpha {
+		n++
+		orderings = orderings * float64(2*n) * float64(2*n-1) / float64(n*n)
+	}
+
+	return n
+}
+
+func (c comparison) thresholdOnly() bool {
+	return !c.tested
+}
+
+// runCompare compares every cell the two result sets share. A significant
+// change for the worse above the threshold is a regression and fails the
+// command.
+func runCompare(opts *options) error {
+	if len(opts.files) != 2 {
+		return fmt.Errorf("want the old and the new results, got %d arguments", len(opts.files))
+	}
+	if opts.alpha <= 0 || opts.alpha >= 1 {
+		return fmt.Errorf("-alpha %g is not between 0 and 1", opts.alpha)
+	}
+
+	oldRecords, err := readRecords(opts.files[:1])
+	if err != nil {
+		return err
+	}
+	newRecords, err := readRecords(opts.files[1:])
+	if err != nil {
+		return err
+	}
+
+	comparisons, onlyOld, onlyNew := compare(oldRecords, newRecords, opts.alpha)
+	if len(comparisons) == 0 {
+		return fmt.Errorf("the result sets share no cells")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "cell\tmetric\told\tnew\tdelta\t\t")
+
+	var regressions, unrepeated int
+	for _, c := range comparisons {
+		delta, note := "~", fmt.Sprintf("(p=%.3f n=%d+%d)", c.p, c.old.N, c.new.N)
+		if c.significant || c.thresholdOnly() {
+			delta = fmt.Sprintf("%+.2f%%", 100*c.delta)
+		}
+		if c.thresholdOnly() {
+			note = fmt.Sprintf("(n=%d+%d, threshold only)", c.old.N, c.new.N)
+		}
+
+		worsening, isCost := c.worsening()
+		if isCost && c.thresholdOnly() {
+			unrepeated++
+		}
+		if isCost && (c.significant || c.thresholdOnly()) && 100*worsening > opts.threshold {
+			regressions++
+			note += " REGRESSION"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", c.key, c.key.metric, formatMean(c.old), formatMean(c.new), delta, note)
+	}
+
+	if err = w.Flush(); err != nil {
+		return err
+	}
+
+	if onlyOld+onlyNew > 0 {
+		fmt.Printf("\n%d cells only in the old results, %d only in the new ones\n", onlyOld, onlyNew)
+	}
+
+	if unrepeated > 0 {
+		minN := minRepetitions(opts.alpha)
+		fmt.Fprintf(os.Stderr, "\nWARNING: %d cells have fewer than %d repetitions on a side, so no significance test ran on them;\n"+
+			"they count as regressions on the threshold alone. Run both sides with -reps %d or more for a real test at -alpha %g.\n",
+			unrepeated, minN, minN, opts.alpha)
+	}
+
+	if regressions > 0 {
+		return fmt.Errorf("%d regressions above %g%%", regressions, opts.threshold)
+	}
+
+	return nil
+}
+
+func compare(oldRecords, newRecords []results.Record, alpha float64) ([]comparison, int, int) {
+	group := func(records []results.Record) map[cellKey][]float64 {
+		cells := map[cellKey][]float64{}
+		for _, r := range records {
+			cells[keyOf(r)] = append(cells[keyOf(r)], r.Value)
+		}
+		return cells
+	}
+
+	oldCells, newCells := group(oldRecords), group(newRecords)
+	minN := minRepetitions(alpha)
+
+	var (
+		comparisons []comparison
+		onlyOld     int
+	)
+
+	for key, oldValues := range oldCells {
+		newValues, ok := newCells[key]
+		if !ok {
+			onlyOld++
+			continue
+		}
+
+		c := comparison{key: key, old: stats.Summarize(oldValues), new: stats.Summarize(newValues), p: 1}
+		if c.old.Mean != 0 {
+			c.delta = (c.new.Mean - c.old.Mean) / c.old.Mean
+		}
+
+		if c.tested = len(oldValues) >= minN && len(newValues) >= minN; c.tested {
+			_, c.p = stats.MannWhitneyU(oldValues, newValues)
+			c.significant = c.p < alpha
+		}
+
+		comparisons = append(comparisons, c)
+	}
+
+	slices.SortFunc(comparisons, func(a, b comparison) int {
+		return cmp.Or(
+			strings.Compare(a.key.experiment, b.key.experiment),
+			strings.Compare(a.key.metric, b.key.metric),
+			compareSeries(a.key.variant, b.key.variant),
+			strings.Compare(a.key.keyKind, b.key.keyKind),
+			cmp.Compare(a.key.loadFactor, b.key.loadFactor),
+			cmp.Compare(a.key.size, b.key.size),
+			strings.Compare(a.key.labels, b.key.labels),
+		)
+	})
+
+	return comparisons, onlyOld, len(newCells) - len(comparisons)
+}
+
+// costMetrics are the lower-is-better metrics, gainMetrics the
+// higher-is-better ones. Only these can regress: counts such as operations,
+// histogram bins or observed_buckets have no better direction.
+var (
+	costMetrics = []string{
+		"collisions", "probes", "time", "tombstones", "max_length", "memory", "memory_per_entry", "resize",
+		"latency_mean", "latency_p50", "latency_p90", "latency_p99", "latency_p999", "latency_max",
+		"ns/op", "ns/insert", "B/op", "allocs/op",
+	}
+	gainMetrics = []string{"throughput"}
+)
+
+// worsening is the relative change in the bad direction, and false for a
+// metric that is neither a cost nor a gain.
+func (c comparison) worsening() (float64, bool) {
+	switch {
+	case slices.Contains(costMetrics, c.key.metric):
+		return c.delta, true
+	case slices.Contains(gainMetrics, c.key.metric):
+		return -c.delta, true
+	default:
+		return 0, false
+	}
+}
+
+func formatMean(s stats.Summary) string {
+	if s.N < 2 {
+		return fmt.Sprintf("%.4g", s.Mean)
+	}
+
+	return fmt.Sprintf("%.4g ±%.0f%%", s.Mean, 100*(s.CIHigh-s.Mean)/max(s.Mean, 1e-300))
+}
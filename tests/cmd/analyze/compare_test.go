@@ -0,0 +1,72 @@
+package main
+
+import (
+	"analyze/internal/results"
+	"testing"
+)
+
+func TestCompareThresholdOnly(t *testing.T) {
+	record := func(metric string, value float64) results.Record {
+		return results.Record{Experiment: "Collision", Method: "Chain", KeyKind: "RandomKey", Size: 1000, Metric: metric, Value: value}
+	}
+
+	comparisons, _, _ := compare(
+		[]results.Record{record("collisions", 100), record("operations", 100), record("throughput", 100)},
+		[]results.Record{record("collisions", 120), record("operations", 200), record("throughput", 80)},
+		0.05,
+	)
+
+	want := map[string]struct {
+		worsening float64
+		isCost    bool
+	}{
+		"collisions": {0.2, true},
+		"operations": {0, false},
+		"throughput": {0.2, true},
+	}
+
+	for _, c := range comparisons {
+		if !c.thresholdOnly() {
+			t.Errorf("%s: single repetitions not threshold only", c.key.metric)
+		}
+
+		worsening, isCost := c.worsening()
+		w := want[c.key.metric]
+		if isCost != w.isCost || (isCost && (worsening < w.worsening-1e-9 || worsening > w.worsening+1e-9)) {
+			t.Errorf("%s: worsening %v, %v, want %v, %v", c.key.metric, worsening, isCost, w.worsening, w.isCost)
+		}
+	}
+}
+
+func TestMinRepetitions(t *testing.T) {
+	for alpha, want := range map[float64]int{0.5: 2, 0.2: 3, 0.05: 4, 0.01: 5} {
+		if got := minRepetitions(alpha); got != want {
+			t.Errorf("alpha %g: %d repetitions, want %d", alpha, got, want)
+		}
+	}
+}
+
+// Three repetitions a side cannot reach p < 0.05 even when they do not
+// overlap, four can.
+func TestCompareRepetitions(t *testing.T) {
+	records := func(n int, base float64) []results.Record {
+		var records []results.Record
+		for i := range n {
+			records = append(records, results.Record{Experiment: "Collision", Method: "Chain", KeyKind: "RandomKey",
+				Size: 1000, Metric: "collisions", Value: base + float64(i), Repetition: i})
+		}
+		return records
+	}
+
+	for n, tested := range map[int]bool{3: false, 4: true} {
+		comparisons, _, _ := compare(records(n, 100), records(n, 200), 0.05)
+		c := comparisons[0]
+
+		if c.thresholdOnly() == tested {
+			t.Errorf("%d repetitions: threshold only %v, want %v", n, c.thresholdOnly(), !tested)
+		}
+		if c.significant != tested {
+			t.Errorf("%d repetitions: significant %v (p=%.3f), want %v", n, c.significant, c.p, tested)
+		}
+	}
+}
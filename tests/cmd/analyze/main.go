@@ -0,0 +1,412 @@
+package main
+
+import (
+	"analyze/cmd/test"
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const usage = `usage: analyze <command> [flags]
+
+commands:
+  run         collisions, probes and layout analysis of the whole matrix
+  collisions  collision counts per size and over the load-factor sweep
+  probes      successful and unsuccessful probes over the load-factor sweep
+  layout      cluster, displacement and chain length distributions
+  bench       benchmarks printed in the go test -bench format
+  parse       go test -bench output, from files or stdin, as JSON Lines records
+  plot        SVG charts of the benchmark records or go test -bench output
+  report      a single HTML file with charts, rankings, theory and metadata
+  compare     the cells of two result sets, failing on significant regressions
+  workload    throughput and latencies under YCSB-style operation mixes
+  replay      binary operation traces replayed against every method
+  churn       probes, tombstones and time over a long delete-insert churn
+  latency     p50 to p99.9 and max latencies of single inserts and lookups
+  resize      every resize and rehash of a growing table, with cause and time
+  memory      bytes per entry the tables hold, by size
+
+run "analyze <command> -h" to see the flags of a command
+`
+
+type options struct {
+	set         map[string]bool
+	configPath  string
+	config      *test.Config
+	methods     string
+	keys        string
+	sizes       string
+	loadFactors string
+	seed        int64
+	only        string
+	out         string
+	format      string
+	sweep       string
+	sweepSize   int
+	samples     int
+	repetitions int
+	operations  string
+	benchtime   string
+	files       []string
+	threshold   float64
+	alpha       float64
+	zipfSkew    float64
+	hotFraction float64
+	hotShare    float64
+	presets     string
+	workloadOps int
+	duration    time.Duration
+	churnSize   int
+	rounds      int
+	churnPoints int
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+
+	var run func(opts *options) error
+	switch command {
+	case "run":
+		run = func(opts *options) error {
+			if opts.config != nil {
+				return opts.config.Run()
+			}
+
+			test.RunCollisionsTest()
+			test.RunProbesTest()
+			test.RunLayoutTest()
+			return nil
+		}
+	case "collisions":
+		run = func(*options) error { test.RunCollisionsTest(); return nil }
+	case "probes":
+		run = func(*options) error { test.RunProbesTest(); return nil }
+	case "layout":
+		run = func(*options) error { test.RunLayoutTest(); return nil }
+	case "bench":
+		run = runBench
+	case "parse":
+		run = runParse
+	case "plot":
+		run = runPlot
+	case "report":
+		run = runReport
+	case "compare":
+		run = runCompare
+	case "workload":
+		run = func(*options) error { test.RunWorkloads(); return nil }
+	case "churn":
+		run = func(*options) error { test.RunChurnTest(); return nil }
+	case "latency":
+		run = func(*options) error { test.RunLatencyTest(); return nil }
+	case "resize":
+		run = func(*options) error { test.RunResizeTest(); return nil }
+	case "memory":
+		run = func(*options) error { test.RunMemoryTest(); return nil }
+	case "replay":
+		run = func(opts *options) error {
+			if len(opts.files) == 0 {
+				return errors.New("no trace files given")
+			}
+			return test.RunReplay(opts.files)
+		}
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "analyze: unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+
+	opts := parseFlags(command, args)
+
+	if err := apply(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "analyze %s: %v\n", command, err)
+		os.Exit(2)
+	}
+
+	closeSinks, err := test.OpenSinks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "analyze %s: %v\n", command, err)
+		os.Exit(1)
+	}
+
+	err = errors.Join(run(opts), closeSinks())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "analyze %s: %v\n", command, err)
+		os.Exit(1)
+	}
+}
+
+func parseFlags(command string, args []string) *options {
+	opts := &options{set: map[string]bool{}}
+	fs := flag.NewFlagSet("analyze "+command, flag.ExitOnError)
+
+	// These commands only read result files.
+	switch command {
+	case "parse":
+		fs.StringVar(&opts.out, "out", "", "file to write the records to (default stdout)")
+		_ = fs.Parse(args)
+		opts.files = fs.Args()
+		return opts
+	case "plot":
+		fs.StringVar(&opts.out, "out", filepath.Join(test.OutputDir, "plots"), "directory to write the SVG charts to")
+		_ = fs.Parse(args)
+		opts.files = fs.Args()
+		return opts
+	case "report":
+		fs.StringVar(&opts.out, "out", "report.html", "HTML file to write")
+		_ = fs.Parse(args)
+		opts.files = fs.Args()
+		return opts
+	case "compare":
+		fs.Float64Var(&opts.threshold, "threshold", 5, "percent a cell may get worse before it counts as a regression")
+		fs.Float64Var(&opts.alpha, "alpha", 0.05, "significance level of the Mann-Whitney U test")
+		_ = fs.Parse(args)
+		opts.files = fs.Args()
+		return opts
+	}
+
+	if command == "run" {
+		fs.StringVar(&opts.configPath, "config", "", "JSON experiment config; flags given explicitly override it")
+	}
+
+	fs.StringVar(&opts.methods, "methods", "", "comma-separated methods to run (default all)")
+	fs.StringVar(&opts.keys, "keys", "", "comma-separated key generators to use (default all)")
+	fs.StringVar(&opts.sizes, "sizes", "", "comma-separated table sizes (default "+joinInts(test.Sizes)+")")
+	fs.StringVar(&opts.loadFactors, "lf", "", "comma-separated load factors (default "+joinFloats(test.LoadFactors)+")")
+	fs.Int64Var(&opts.seed, "seed", test.DefaultSeed, "master seed every experiment cell derives its own seed from")
+	fs.StringVar(&opts.only, "only", "", "regexp selecting cells by name: <Experiment>/<Method>/<KeyKind>, or <Operation>/<Method>-<KeyKind>-<LoadFactor>-<Size> for bench")
+
+	switch command {
+	case "workload":
+		fs.StringVar(&opts.presets, "presets", "", "comma-separated workloads, any of "+strings.Join(test.Presets.Names(), ", ")+" (default all)")
+		fs.IntVar(&opts.workloadOps, "operations", test.WorkloadOperations, "operations of every cell after loading the records, 0 for no limit")
+		fs.DurationVar(&opts.duration, "duration", test.WorkloadDuration, "run time of every cell, 0 for no limit")
+		fs.Float64Var(&opts.zipfSkew, "zipf", test.ZipfSkew, "skew s > 1 of the zipfian and latest distributions")
+	case "churn":
+		fs.IntVar(&opts.churnSize, "churn-size", test.ChurnSize, "capacity of the churned table")
+		fs.IntVar(&opts.rounds, "rounds", test.ChurnRounds, "capacities worth of keys to delete and replace")
+		fs.IntVar(&opts.churnPoints, "points", test.ChurnSamples, "samples of the time series")
+	}
+
+	switch command {
+	case "bench":
+		fs.StringVar(&opts.operations, "ops", "", "comma-separated benchmarks, any of "+strings.Join(test.Benchmarks.Names(), ", ")+" (default all)")
+		fs.StringVar(&opts.benchtime, "benchtime", "", "run time of every benchmark, as for go test -benchtime")
+		fs.Float64Var(&opts.zipfSkew, "zipf", test.ZipfSkew, "skew s > 1 of the ZipfGet and LatestGet lookups")
+		fs.Float64Var(&opts.hotFraction, "hot-fraction", test.HotFraction, "share of the keys that are hot in HotSetGet")
+		fs.Float64Var(&opts.hotShare, "hot-share", test.HotShare, "share of the HotSetGet lookups that go to hot keys")
+		fs.StringVar(&opts.out, "out", test.OutputDir, "output directory of the jsonl records")
+		fs.StringVar(&opts.format, "format", "csv", "comma-separated output formats: "+strings.Join(test.OutputFormats, ", ")+"; jsonl also writes the results as records")
+	default:
+		fs.StringVar(&opts.out, "out", test.OutputDir, "output directory")
+		fs.StringVar(&opts.format, "format", "csv", "comma-separated output formats: "+strings.Join(test.OutputFormats, ", "))
+		fs.StringVar(&opts.sweep, "sweep", "", "load factors of the probes and collisions sweep as from:to:step, e.g. 0.05:0.99:0.01")
+		fs.IntVar(&opts.sweepSize, "sweep-size", test.SweepSize, "table size used by the sweep")
+		fs.IntVar(&opts.samples, "samples", test.SweepSamples, "lookups sampled per load factor")
+		fs.IntVar(&opts.repetitions, "reps", test.Repetitions, "repetitions of every cell, each with its own derived seed")
+	}
+
+	_ = fs.Parse(args)
+	opts.files = fs.Args()
+
+	fs.Visit(func(f *flag.Flag) { opts.set[f.Name] = true })
+
+	return opts
+}
+
+func apply(opts *options) error {
+	if opts.configPath != "" {
+		config, err := test.LoadConfig(opts.configPath)
+		if err != nil {
+			return err
+		}
+
+		if err = config.Apply(); err != nil {
+			return err
+		}
+
+		opts.config = &config
+	}
+
+	if err := test.SelectMethods(splitList(opts.methods)); err != nil {
+		return err
+	}
+
+	if err := test.SelectKeyGens(splitList(opts.keys)); err != nil {
+		return err
+	}
+
+	if opts.sizes != "" {
+		sizes, err := parseList(opts.sizes, strconv.Atoi)
+		if err != nil {
+			return fmt.Errorf("sizes: %w", err)
+		}
+		for _, size := range sizes {
+			if size < 1 {
+				return fmt.Errorf("sizes: %d is not positive", size)
+			}
+		}
+		test.Sizes = sizes
+	}
+
+	if opts.loadFactors != "" {
+		loadFactors, err := parseList(opts.loadFactors, func(s string) (float64, error) { return strconv.ParseFloat(s, 64) })
+		if err != nil {
+			return fmt.Errorf("load factors: %w", err)
+		}
+		for _, loadFactor := range loadFactors {
+			if loadFactor <= 0 || loadFactor > 1 {
+				return fmt.Errorf("load factors: %v is not in (0, 1]", loadFactor)
+			}
+		}
+		test.LoadFactors = loadFactors
+	}
+
+	if opts.sweep != "" {
+		sweep, err := test.ParseSweep(opts.sweep)
+		if err != nil {
+			return err
+		}
+		test.SweepLoadFactors = sweep.Values()
+	}
+
+	if opts.set["out"] {
+		test.OutputDir = opts.out
+	}
+	if opts.set["format"] {
+		if err := test.SetFormats(splitList(opts.format)); err != nil {
+			return err
+		}
+	}
+	if opts.set["sweep-size"] {
+		if opts.sweepSize < 1 {
+			return fmt.Errorf("sweep-size: %d is not positive", opts.sweepSize)
+		}
+		test.SweepSize = opts.sweepSize
+	}
+	if opts.set["samples"] {
+		if opts.samples < 1 {
+			return fmt.Errorf("samples: %d is not positive", opts.samples)
+		}
+		test.SweepSamples = opts.samples
+	}
+	if opts.set["reps"] {
+		if opts.repetitions < 1 {
+			return fmt.Errorf("reps: %d is not positive", opts.repetitions)
+		}
+		test.Repetitions = opts.repetitions
+	}
+	if opts.set["only"] {
+		if err := test.SetOnly(opts.only); err != nil {
+			return err
+		}
+	}
+	if opts.set["seed"] {
+		test.SetSeed(opts.seed)
+	}
+	if opts.set["zipf"] || opts.set["hot-fraction"] || opts.set["hot-share"] {
+		if err := test.SetLookupParams(opts.zipfSkew, cmp.Or(opts.hotFraction, test.HotFraction), cmp.Or(opts.hotShare, test.HotShare)); err != nil {
+			return err
+		}
+	}
+	if opts.set["churn-size"] || opts.set["rounds"] || opts.set["points"] {
+		if opts.churnSize < 1 || opts.rounds < 1 || opts.churnPoints < 1 {
+			return errors.New("churn-size, rounds and points must be positive")
+		}
+		test.ChurnSize, test.ChurnRounds, test.ChurnSamples = opts.churnSize, opts.rounds, opts.churnPoints
+	}
+	if err := test.SelectPresets(splitList(opts.presets)); err != nil {
+		return err
+	}
+	if opts.set["operations"] || opts.set["duration"] {
+		if opts.workloadOps < 0 || opts.duration < 0 {
+			return errors.New("operations and duration must not be negative")
+		}
+		if opts.workloadOps == 0 && opts.duration == 0 {
+			return errors.New("one of operations and duration must be set")
+		}
+		test.WorkloadOperations, test.WorkloadDuration = opts.workloadOps, opts.duration
+	}
+
+	return nil
+}
+
+func runBench(opts *options) error {
+	operations := splitList(opts.operations)
+	if len(operations) == 0 {
+		operations = test.Benchmarks.Names()
+	}
+
+	// testing.Benchmark needs the flags of the testing package, which only
+	// go test registers on its own.
+	testing.Init()
+
+	if opts.benchtime != "" {
+		if err := flag.Set("test.benchtime", opts.benchtime); err != nil {
+			return fmt.Errorf("benchtime: %w", err)
+		}
+	}
+
+	return test.RunBenchmarks(os.Stdout, operations)
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func parseList[T any](s string, parse func(string) (T, error)) ([]T, error) {
+	var values []T
+
+	for _, item := range splitList(s) {
+		v, err := parse(item)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	return values, nil
+}
+
+func joinInts(values []int) string {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = strconv.Itoa(v)
+	}
+
+	return strings.Join(items, ",")
+}
+
+func joinFloats(values []float64) string {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return strings.Join(items, ",")
+}
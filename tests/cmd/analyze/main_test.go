@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestApplyRejectsFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		opts options
+	}{
+		{"zero size", options{sizes: "100,0"}},
+		{"negative size", options{sizes: "-5"}},
+		{"zero load factor", options{loadFactors: "0"}},
+		{"load factor above one", options{loadFactors: "0.5,1.5"}},
+		{"zero sweep size", options{sweepSize: 0, set: map[string]bool{"sweep-size": true}}},
+		{"zero samples", options{samples: 0, set: map[string]bool{"samples": true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.opts.set == nil {
+				tt.opts.set = map[string]bool{}
+			}
+
+			if err := apply(&tt.opts); err == nil {
+				t.Error("accepted")
+			}
+		})
+	}
+}
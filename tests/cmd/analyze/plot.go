@@ -0,0 +1,221 @@
+package main
+
+import (
+	"analyze/cmd/test"
+	"analyze/internal/plot"
+	"analyze/internal/results"
+	"analyze/internal/stats"
+	"bytes"
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	sizeLabel   = "Количество элементов"
+	timeLabel   = "Среднее время 1 операции (ns)"
+	memoryLabel = "Количество выделенной памяти (bytes)"
+)
+
+// graphic is one chart per key kind and load factor of a benchmark metric.
+// Methods in split get a panel of their own above the smallest load factor,
+// where they would otherwise flatten every other line.
+type graphic struct {
+	name      string
+	operation string
+	metric    string
+	yLabel    string
+	split     []string
+}
+
+// graphics are the charts graphics/main.py made, followed by the skewed
+// lookups.
+var graphics = []graphic{
+	{"InsertReserve", "InsertReserve", "ns/insert", timeLabel, nil},
+	{"InsertNoReserve", "InsertNoReserve", "ns/insert", timeLabel, []string{"Cuckoo"}},
+	{"SuccessGet", "SuccessGet", "ns/op", timeLabel, nil},
+	{"UnsuccessGet", "UnsuccessGet", "ns/op", timeLabel, nil},
+	{"Delete", "Delete", "ns/op", timeLabel, nil},
+	{"AllocateMemoryInsertNoReserve", "InsertNoReserve", "B/op", memoryLabel, []string{"Cuckoo"}},
+	{"UniformGet", "UniformGet", "ns/op", timeLabel, nil},
+	{"ZipfGet", "ZipfGet", "ns/op", timeLabel, nil},
+	{"HotSetGet", "HotSetGet", "ns/op", timeLabel, nil},
+	{"LatestGet", "LatestGet", "ns/op", timeLabel, nil},
+}
+
+var methodTitles = map[string]string{
+	"Chain":     "Chain method",
+	"Cuckoo":    "Cuckoo method",
+	"Double":    "Double hashing",
+	"Hopscotch": "Hopscotch hashing",
+	"RobinHood": "Robin Hood hashing",
+}
+
+func runPlot(opts *options) error {
+	records, err := readRecords(opts.files)
+	if err != nil {
+		return err
+	}
+
+	var written int
+	for _, g := range graphics {
+		for _, chart := range g.charts(records) {
+			var svg bytes.Buffer
+			if err = chart.WriteSVG(&svg); err != nil {
+				return err
+			}
+
+			path := filepath.Join(opts.out, g.name, chart.name+".svg")
+			if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			if err = os.WriteFile(path, svg.Bytes(), 0o644); err != nil {
+				return err
+			}
+			written++
+		}
+	}
+
+	if written == 0 {
+		return fmt.Errorf("no benchmark records to plot")
+	}
+
+	return nil
+}
+
+type chartKey struct {
+	keyKind    string
+	loadFactor float64
+}
+
+type namedChart struct {
+	name string
+	plot.Chart
+}
+
+// charts makes one chart per key kind and load factor, named
+// "<KeyKind>_<LoadFactor>" like the files the Python scripts wrote.
+func (g graphic) charts(records []results.Record) []namedChart {
+	// chart -> series -> size -> repetitions
+	cells := map[chartKey]map[string]map[int][]float64{}
+	var smallestLoadFactor float64
+
+	for _, r := range records {
+		if r.Experiment != g.operation || r.Metric != g.metric {
+			continue
+		}
+
+		key := chartKey{r.KeyKind, r.LoadFactor}
+		if cells[key] == nil {
+			cells[key] = map[string]map[int][]float64{}
+		}
+
+		name := seriesName(r)
+		if cells[key][name] == nil {
+			cells[key][name] = map[int][]float64{}
+		}
+		cells[key][name][r.Size] = append(cells[key][name][r.Size], r.Value)
+
+		if smallestLoadFactor == 0 || r.LoadFactor < smallestLoadFactor {
+			smallestLoadFactor = r.LoadFactor
+		}
+	}
+
+	keys := make([]chartKey, 0, len(cells))
+	for key := range cells {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b chartKey) int {
+		return cmp.Or(strings.Compare(a.keyKind, b.keyKind), cmp.Compare(a.loadFactor, b.loadFactor))
+	})
+
+	charts := make([]namedChart, 0, len(keys))
+	for _, key := range keys {
+		bySeries := cells[key]
+		chart := namedChart{name: fmt.Sprintf("%s_%.2f", key.keyKind, key.loadFactor)}
+		chart.Chart = plot.Chart{
+			XLabel: sizeLabel,
+			YLabel: g.yLabel,
+			LogX:   true,
+		}
+
+		if key.loadFactor > smallestLoadFactor {
+			for _, method := range g.split {
+				chart.Split = append(chart.Split, methodTitle(method))
+			}
+		}
+
+		for _, name := range sortedSeries(bySeries) {
+			series := plot.Series{Name: methodTitle(name)}
+
+			sizes := make([]int, 0, len(bySeries[name]))
+			for size := range bySeries[name] {
+				sizes = append(sizes, size)
+			}
+			slices.Sort(sizes)
+
+			for _, size := range sizes {
+				s := stats.Summarize(bySeries[name][size])
+				point := plot.Point{X: float64(size), Y: s.Mean}
+				if s.N > 1 {
+					point.Low, point.High = s.CILow, s.CIHigh
+				}
+				series.Points = append(series.Points, point)
+			}
+
+			chart.Series = append(chart.Series, series)
+		}
+
+		charts = append(charts, chart)
+	}
+
+	return charts
+}
+
+// sortedSeries returns the variant names of a map in registry order.
+func sortedSeries[V any](bySeries map[string]V) []string {
+	names := make([]string, 0, len(bySeries))
+	for name := range bySeries {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, compareSeries)
+
+	return names
+}
+
+// seriesName is the variant name of the record, e.g. "Chain.SplitMix".
+func seriesName(r results.Record) string {
+	if _, ok := test.Methods.Lookup(r.Method); !ok {
+		return cmp.Or(r.Method, "unknown")
+	}
+
+	return test.VariantName(r.Method, r.Hasher)
+}
+
+func methodTitle(variant string) string {
+	method, hasherName, _ := strings.Cut(variant, ".")
+
+	title := cmp.Or(methodTitles[method], method)
+	if hasherName != "" {
+		title += " (" + hasherName + ")"
+	}
+
+	return title
+}
+
+// compareSeries keeps methods in registry order, so every chart uses the same
+// colors for the same methods.
+func compareSeries(a, b string) int {
+	index := func(variant string) int {
+		method, _, _ := strings.Cut(variant, ".")
+		if i := slices.Index(test.Methods.Names(), method); i >= 0 {
+			return i
+		}
+		return len(test.Methods)
+	}
+
+	return cmp.Or(cmp.Compare(index(a), index(b)), strings.Compare(a, b))
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"analyze/cmd/test"
+	"analyze/internal/benchfmt"
+	"analyze/internal/results"
+	"bufio"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runParse turns benchmark output into the records the other commands
+// write, so results can be read without relying on the exact test names.
+func runParse(opts *options) error {
+	records, err := readRecords(opts.files)
+	if err != nil {
+		return err
+	}
+
+	var sink results.Sink = results.NewJSONLWriter(os.Stdout)
+	if opts.out != "" {
+		if sink, err = results.NewJSONL(opts.out); err != nil {
+			return err
+		}
+	}
+
+	for _, record := range records {
+		if err = sink.Write(record); err != nil {
+			sink.Close()
+			return err
+		}
+	}
+
+	return sink.Close()
+}
+
+// input is one file read by parse, plot or report. Its metadata comes from
+// the config lines of go test output or from the .meta.json sidecar of a
+// JSON Lines file.
+type input struct {
+	path     string
+	records  []results.Record
+	metadata []field
+}
+
+type field struct {
+	Key, Value string
+}
+
+// readRecords reads every file, or stdin without files. A file is either
+// JSON Lines records or go test -bench output. Records without a hasher get
+// the default one of their method.
+func readRecords(files []string) ([]results.Record, error) {
+	inputs, err := readInputs(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []results.Record
+	for _, in := range inputs {
+		records = append(records, in.records...)
+	}
+
+	return records, nil
+}
+
+func readInputs(files []string) ([]input, error) {
+	if len(files) == 0 {
+		in, err := decodeInput(os.Stdin)
+		in.path = "stdin"
+
+		return []input{in}, err
+	}
+
+	var paths []string
+	for _, path := range files {
+		expanded, err := expandPath(path)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, expanded...)
+	}
+
+	inputs := make([]input, 0, len(paths))
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var in input
+		if filepath.Ext(path) == ".csv" {
+			in.records, err = decodeCSV(path, file)
+		} else {
+			in, err = decodeInput(file)
+		}
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		in.path = path
+
+		if in.metadata == nil {
+			if in.metadata, err = readMetadata(path + ".meta.json"); err != nil {
+				return nil, err
+			}
+		}
+
+		inputs = append(inputs, in)
+	}
+
+	return inputs, nil
+}
+
+// expandPath lists the result files of a directory: JSON Lines, benchmark
+// output (.txt) and CSVs. The CSVs are skipped when there are JSON Lines
+// files, which hold the same values and their repetitions.
+func expandPath(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files, csvs []string
+	err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return err
+		}
+
+		switch filepath.Ext(file) {
+		case ".jsonl", ".txt":
+			files = append(files, file)
+		case ".csv":
+			csvs = append(csvs, file)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.ContainsFunc(files, func(file string) bool { return filepath.Ext(file) == ".jsonl" }) {
+		files = append(files, csvs...)
+	}
+
+	return files, nil
+}
+
+// csvDimensions are CSV columns that tell which cell a row belongs to rather
+// than what was measured in it; summaryColumns only describe the spread of a
+// metric. csvMetrics names the columns whose records carry another metric.
+var (
+	csvDimensions  = []string{"length", "distance", "position", "table", "op"}
+	summaryColumns = []string{"std", "min", "max", "ci_low", "ci_high"}
+	csvMetrics     = map[string]string{"predicted": "predicted_probes"}
+)
+
+// decodeCSV reads a CSV of the result tree. The cell is taken from the path,
+// <Experiment>/<Method>[/<LoadFactor>]/<KeyKind>.csv, read from the end so
+// the tree may live anywhere; every other column of a row becomes a record.
+func decodeCSV(path string, r io.Reader) ([]results.Record, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	dir := filepath.Dir(path)
+	cell := results.Record{KeyKind: strings.TrimSuffix(filepath.Base(path), ".csv")}
+
+	if loadFactor, err := strconv.ParseFloat(filepath.Base(dir), 64); err == nil {
+		cell.LoadFactor = loadFactor
+		dir = filepath.Dir(dir)
+	}
+	cell.Method, cell.Hasher, _ = strings.Cut(filepath.Base(dir), ".")
+	cell.Experiment = filepath.Base(filepath.Dir(dir))
+
+	header := rows[0]
+
+	var records []results.Record
+	for _, row := range rows[1:] {
+		record := cell
+
+		var metrics []int
+		for i, column := range header {
+			if slices.Contains(csvDimensions, column) {
+				record.Labels = map[string]string{column: row[i]}
+				continue
+			}
+
+			value, err := strconv.ParseFloat(row[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", column, err)
+			}
+
+			switch {
+			case column == "size":
+				record.Size = int(value)
+			case column == "repetition":
+				record.Repetition = int(value)
+			case column == "load_factor" && cell.LoadFactor == 0:
+				record.LoadFactor = value
+			case !slices.Contains(summaryColumns, column):
+				metrics = append(metrics, i)
+			}
+		}
+
+		for _, i := range metrics {
+			record.Metric = cmp.Or(csvMetrics[header[i]], header[i])
+			record.Value, _ = strconv.ParseFloat(row[i], 64)
+			records = append(records, record)
+		}
+	}
+
+	fillHashers(records)
+
+	return records, nil
+}
+
+func decodeInput(r io.Reader) (input, error) {
+	var (
+		in     input
+		reader = bufio.NewReader(r)
+	)
+
+	for {
+		b, err := reader.Peek(1)
+		if err == io.EOF {
+			return in, nil
+		} else if err != nil {
+			return in, err
+		}
+
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = reader.ReadByte()
+			continue
+		case '{':
+			in.records, err = results.ReadJSONL(reader)
+		default:
+			var parsed []benchfmt.Result
+			if parsed, err = benchfmt.Parse(reader); err == nil {
+				in.records = benchfmt.Records(parsed)
+				if len(parsed) > 0 {
+					in.metadata = configFields(parsed[0].Config)
+				}
+			}
+		}
+
+		fillHashers(in.records)
+
+		return in, err
+	}
+}
+
+func fillHashers(records []results.Record) {
+	for i, record := range records {
+		if method, ok := test.Methods.Lookup(record.Method); ok && record.Hasher == "" {
+			records[i].Hasher = method.DefaultHasher
+		}
+	}
+}
+
+func configFields(config map[string]string) []field {
+	keys := slices.Sorted(maps.Keys(config))
+
+	fields := make([]field, len(keys))
+	for i, key := range keys {
+		fields[i] = field{key, config[key]}
+	}
+
+	return fields
+}
+
+// readMetadata reads a sidecar; a missing one is no error.
+func readMetadata(path string) ([]field, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var m test.Metadata
+	if err = json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	fields := []field{
+		{"created", m.CreatedAt.Format(time.RFC3339)},
+		{"go", m.GoVersion},
+		{"os/arch", m.OS + "/" + m.Arch},
+		{"cpu", m.CPU},
+		{"cpus", strconv.Itoa(m.NumCPU)},
+		{"gomaxprocs", strconv.Itoa(m.GOMAXPROCS)},
+		{"commit", m.Commit},
+		{"seed", strconv.FormatInt(m.Seed, 10)},
+		{"methods", strings.Join(m.Config.Methods, ", ")},
+		{"key generators", strings.Join(m.Config.KeyGens, ", ")},
+		{"sizes", joinInts(m.Config.Sizes)},
+		{"load factors", joinFloats(m.Config.LoadFactors)},
+		{"sweep load factors", joinFloats(m.Config.SweepLoadFactors)},
+		{"sweep size", strconv.Itoa(m.Config.SweepSize)},
+		{"sweep samples", strconv.Itoa(m.Config.SweepSamples)},
+		{"repetitions", strconv.Itoa(m.Config.Repetitions)},
+	}
+	if m.Config.Only != "" {
+		fields = append(fields, field{"only", m.Config.Only})
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"analyze/internal/results"
+	"analyze/internal/stats"
+	"analyze/internal/theory"
+	"bytes"
+	"cmp"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+//go:embed report.html
+var reportTemplate string
+
+type report struct {
+	Created  string
+	Inputs   []reportInput
+	Theory   []theoryTable
+	Rankings []rankingTable
+	Charts   []chartGroup
+}
+
+type reportInput struct {
+	Path     string
+	Files    int
+	Records  int
+	Metadata []field
+}
+
+type theoryTable struct {
+	Experiment string
+	Rows       []theoryRow
+}
+
+type theoryRow struct {
+	Method, KeyKind, LoadFactor    string
+	Measured, Predicted, RelErrPct string
+	Off                            bool
+}
+
+type rankingTable struct {
+	Name, Metric string
+	Places       []int
+	Rows         []rankingRow
+}
+
+type rankingRow struct {
+	KeyKind, LoadFactor, Size string
+	Ranked                    []string
+}
+
+type chartGroup struct {
+	Name   string
+	Charts []reportChart
+}
+
+type reportChart struct {
+	Name string
+	SVG  template.HTML
+}
+
+// theoryTolerance is the relative error above which a measurement is marked
+// as disagreeing with its model.
+const theoryTolerance = 0.1
+
+// runReport writes everything the inputs hold into one HTML file, charts
+// included, so it can be shared on its own.
+func runReport(opts *options) error {
+	inputs, err := readInputs(opts.files)
+	if err != nil {
+		return err
+	}
+
+	var records []results.Record
+	r := report{Created: time.Now().UTC().Format(time.RFC3339)}
+
+	for _, in := range inputs {
+		records = append(records, in.records...)
+		r.addInput(in)
+	}
+
+	r.Theory = theoryTables(records)
+
+	for _, g := range graphics {
+		if table := g.ranking(records); len(table.Rows) > 0 {
+			r.Rankings = append(r.Rankings, table)
+		}
+
+		group := chartGroup{Name: g.name}
+		for _, chart := range g.charts(records) {
+			var svg bytes.Buffer
+			if err = chart.WriteSVG(&svg); err != nil {
+				return err
+			}
+			group.Charts = append(group.Charts, reportChart{chart.name, template.HTML(svg.String())})
+		}
+		if len(group.Charts) > 0 {
+			r.Charts = append(r.Charts, group)
+		}
+	}
+
+	tmpl, err := template.New("report").Parse(reportTemplate)
+	if err != nil {
+		return err
+	}
+
+	var html bytes.Buffer
+	if err = tmpl.Execute(&html, r); err != nil {
+		return err
+	}
+
+	return os.WriteFile(opts.out, html.Bytes(), 0o644)
+}
+
+// addInput lists an input under the run it came from. The files of one
+// result tree share their metadata apart from the time they were written.
+func (r *report) addInput(in input) {
+	sameRun := func(a, b []field) bool {
+		a = slices.DeleteFunc(slices.Clone(a), func(f field) bool { return f.Key == "created" })
+		b = slices.DeleteFunc(slices.Clone(b), func(f field) bool { return f.Key == "created" })
+		return slices.Equal(a, b)
+	}
+
+	for i := range r.Inputs {
+		if in.metadata != nil && sameRun(r.Inputs[i].Metadata, in.metadata) {
+			r.Inputs[i].Files++
+			r.Inputs[i].Records += len(in.records)
+			return
+		}
+	}
+
+	r.Inputs = append(r.Inputs, reportInput{Path: in.path, Files: 1, Records: len(in.records), Metadata: in.metadata})
+}
+
+type rankingKey struct {
+	keyKind    string
+	loadFactor float64
+	size       int
+}
+
+// ranking orders the methods of every cell by the mean of the metric, the
+// lowest first.
+func (g graphic) ranking(records []results.Record) rankingTable {
+	cells := map[rankingKey]map[string][]float64{}
+
+	for _, r := range records {
+		if r.Experiment != g.operation || r.Metric != g.metric {
+			continue
+		}
+
+		key := rankingKey{r.KeyKind, r.LoadFactor, r.Size}
+		if cells[key] == nil {
+			cells[key] = map[string][]float64{}
+		}
+		cells[key][seriesName(r)] = append(cells[key][seriesName(r)], r.Value)
+	}
+
+	keys := make([]rankingKey, 0, len(cells))
+	for key := range cells {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b rankingKey) int {
+		return cmp.Or(strings.Compare(a.keyKind, b.keyKind), cmp.Compare(a.loadFactor, b.loadFactor), cmp.Compare(a.size, b.size))
+	})
+
+	table := rankingTable{Name: g.name, Metric: g.metric}
+
+	for _, key := range keys {
+		type ranked struct {
+			name string
+			mean float64
+		}
+
+		var methods []ranked
+		for _, name := range sortedSeries(cells[key]) {
+			methods = append(methods, ranked{name, stats.Summarize(cells[key][name]).Mean})
+		}
+		slices.SortStableFunc(methods, func(a, b ranked) int { return cmp.Compare(a.mean, b.mean) })
+
+		row := rankingRow{KeyKind: key.keyKind, LoadFactor: fmt.Sprintf("%.2f", key.loadFactor), Size: fmt.Sprint(key.size)}
+		for _, m := range methods {
+			row.Ranked = append(row.Ranked, fmt.Sprintf("%s %.2f", m.name, m.mean))
+		}
+
+		table.Rows = append(table.Rows, row)
+		for len(table.Places) < len(row.Ranked) {
+			table.Places = append(table.Places, len(table.Places)+1)
+		}
+	}
+
+	return table
+}
+
+type theoryKey struct {
+	variant    string
+	keyKind    string
+	loadFactor float64
+}
+
+// theoryTables compares the measured probes of the probes experiments with
+// the predictions recorded next to them.
+func theoryTables(records []results.Record) []theoryTable {
+	var tables []theoryTable
+
+	for _, experiment := range []string{"Probes", "ProbesMiss"} {
+		measured := map[theoryKey][]float64{}
+		predicted := map[theoryKey][]float64{}
+
+		for _, r := range records {
+			if r.Experiment != experiment {
+				continue
+			}
+
+			key := theoryKey{seriesName(r), r.KeyKind, r.LoadFactor}
+			switch r.Metric {
+			case "probes":
+				measured[key] = append(measured[key], r.Value)
+			case "predicted_probes":
+				predicted[key] = append(predicted[key], r.Value)
+			}
+		}
+
+		keys := make([]theoryKey, 0, len(measured))
+		for key := range measured {
+			if _, ok := predicted[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		slices.SortFunc(keys, func(a, b theoryKey) int {
+			return cmp.Or(compareSeries(a.variant, b.variant), strings.Compare(a.keyKind, b.keyKind), cmp.Compare(a.loadFactor, b.loadFactor))
+		})
+
+		table := theoryTable{Experiment: experiment}
+		for _, key := range keys {
+			m, p := stats.Summarize(measured[key]).Mean, stats.Summarize(predicted[key]).Mean
+			relErr := theory.RelativeError(m, p)
+
+			table.Rows = append(table.Rows, theoryRow{
+				Method:     key.variant,
+				KeyKind:    key.keyKind,
+				LoadFactor: fmt.Sprintf("%.2f", key.loadFactor),
+				Measured:   fmt.Sprintf("%.3f", m),
+				Predicted:  fmt.Sprintf("%.3f", p),
+				RelErrPct:  fmt.Sprintf("%+.1f%%", 100*relErr),
+				Off:        math.Abs(relErr) > theoryTolerance,
+			})
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables
+}
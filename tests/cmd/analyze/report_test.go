@@ -0,0 +1,48 @@
+package main
+
+import (
+	"analyze/cmd/test"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// A report built from the CSVs alone still compares the probes with their
+// predictions.
+func TestReportFromCSV(t *testing.T) {
+	oldDir, oldSink := test.OutputDir, test.Sink
+	oldSweep, oldSize, oldSamples := test.SweepLoadFactors, test.SweepSize, test.SweepSamples
+	t.Cleanup(func() {
+		test.OutputDir, test.Sink = oldDir, oldSink
+		test.SweepLoadFactors, test.SweepSize, test.SweepSamples = oldSweep, oldSize, oldSamples
+	})
+
+	dir := t.TempDir()
+	test.OutputDir, test.Sink = dir, nil
+	test.SweepLoadFactors, test.SweepSize, test.SweepSamples = []float64{0.5}, 1024, 100
+
+	test.ProbesCountTest("Chain", "RandomKey")
+
+	inputs, err := readInputs([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := theoryTables(inputs[0].records)
+	if len(tables) != 1 || len(tables[0].Rows) != 1 {
+		t.Fatalf("theory tables %+v, want one row of Probes", tables)
+	}
+
+	out := filepath.Join(dir, "report.html")
+	if err = runReport(&options{files: []string{dir}, out: out}); err != nil {
+		t.Fatal(err)
+	}
+	html, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html), `id="theory"`) {
+		t.Error("report has no theory section")
+	}
+}
@@ -1,7 +0,0 @@
-package main
-
-import "analyze/cmd/test"
-
-func main() {
-	test.RunCollisionsAndProbesTest()
-}
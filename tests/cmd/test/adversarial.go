@@ -0,0 +1,103 @@
+package test
+
+import (
+	"analyze/internal/hash_table/hasher"
+	"iter"
+	"math"
+	"math/bits"
+	"math/rand"
+	"sync"
+)
+
+// AdversarialKey is the key kind of the collision attack. Its keys are
+// chosen against the hasher of the table under test, see keyGenFor.
+const AdversarialKey = "Adversarial"
+
+// maxAttackBits is how many low hash bits the attack keys share at most.
+// Every key costs about 2^maxAttackBits hashes to find; sharing 10 bits
+// already multiplies the load of the slots they hit by 1024. The harness
+// tunes the keys to the capacity of every cell up to attackCapacity, and
+// bigger tables get the keys of attackCapacity.
+const (
+	maxAttackBits  = 10
+	attackCapacity = 1 << maxAttackBits
+)
+
+// AdversarialKeys returns keys whose hashes share their low bits, as far as
+// they pick the home slot in a table of the given capacity, so the keys all
+// land on one slot or a few. The attacker knows the hash function but not
+// the seed of the table, so the keys are searched for with seed 0. A hasher
+// whose low bits depend only on the low bits of the key collides them under
+// every seed; a seeded mixing hasher spreads them like any other keys.
+func AdversarialKeys(h hasher.Func, capacity int) KeyGen {
+	mask := uint64(1)<<attackBits(capacity) - 1
+
+	var (
+		mu   sync.Mutex
+		keys []int
+		next uint64
+	)
+
+	// Keys are found once and shared by every generator call.
+	key := func(i int) int {
+		mu.Lock()
+		defer mu.Unlock()
+
+		for len(keys) <= i {
+			if h(next, 0)&mask == 0 {
+				keys = append(keys, int(next))
+			}
+			next++
+		}
+
+		return keys[i]
+	}
+
+	return func(_ *rand.Rand, count int) iter.Seq[int] {
+		return func(yield func(int) bool) {
+			for i := range count {
+				if !yield(key(i)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// attackBits is how many low bits pick the home slot in a table of the
+// capacity, up to maxAttackBits.
+func attackBits(capacity int) int {
+	return min(bits.Len(uint(nextPowerOfTwo(capacity)))-1, maxAttackBits)
+}
+
+// cellCapacity is about the capacity a table grows to when it holds size
+// keys at the load factor.
+func cellCapacity(size int, loadFactor float64) int {
+	return int(math.Ceil(float64(size) / loadFactor))
+}
+
+type attack struct {
+	hasher string
+	bits   int
+}
+
+// attacks holds the attack key generator of every hasher and number of
+// bits, so its keys are searched for once per run.
+var attacks sync.Map
+
+// keyGenFor returns the key generator of keyKind for a table of the method
+// variant with the given capacity. The attack targets the variant's own
+// hasher at that capacity, every other key kind is the same for all tables.
+func keyGenFor(variant string, keyKind string, capacity int) KeyGen {
+	if keyKind != AdversarialKey {
+		return KeyGens.Get(keyKind)
+	}
+
+	target := attack{hasher: variantHasher(variant), bits: attackBits(capacity)}
+	if gen, ok := attacks.Load(target); ok {
+		return gen.(KeyGen)
+	}
+
+	gen, _ := attacks.LoadOrStore(target, AdversarialKeys(Hashers.Get(target.hasher), 1<<target.bits))
+	return gen.(KeyGen)
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"analyze/internal/hash_table/hasher"
+	"testing"
+)
+
+func homes(h hasher.Func, seed uint64, capacity int, keys []int) map[uint64]int {
+	homes := map[uint64]int{}
+	for _, key := range keys {
+		homes[h(uint64(key), seed)&uint64(capacity-1)]++
+	}
+
+	return homes
+}
+
+func attackKeys(variant string, capacity int, count int) []int {
+	var keys []int
+	for key := range keyGenFor(variant, AdversarialKey, capacity)(nil, count) {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func TestAdversarialKeysShareHome(t *testing.T) {
+	for _, capacity := range []int{64, 256, attackCapacity} {
+		keys := attackKeys("Hopscotch", capacity, 200)
+
+		for _, seed := range []uint64{0, 42} {
+			if got := homes(hasher.Multiplicative, seed, capacity, keys); len(got) != 1 {
+				t.Errorf("capacity %d, seed %d: keys on %d homes, want 1", capacity, seed, len(got))
+			}
+		}
+	}
+
+	// Past the search cap the keys share the home bits of attackCapacity.
+	keys := attackKeys("Hopscotch", 4*attackCapacity, 200)
+	if got := homes(hasher.Multiplicative, 0, attackCapacity, keys); len(got) != 1 {
+		t.Errorf("capacity %d: keys on %d homes of %d, want 1", 4*attackCapacity, len(got), attackCapacity)
+	}
+}
+
+// The keys against SplitMix are searched for with seed 0, so they collide
+// there and spread under the seed of a table.
+func TestAdversarialKeysSpreadUnderSeed(t *testing.T) {
+	keys := attackKeys("Chain.SplitMix", attackCapacity, 200)
+
+	if got := homes(hasher.SplitMix, 0, attackCapacity, keys); len(got) != 1 {
+		t.Errorf("seed 0: keys on %d homes, want 1", len(got))
+	}
+	if got := homes(hasher.SplitMix, 42, attackCapacity, keys); len(got) < 150 {
+		t.Errorf("seed 42: keys on %d homes, want them spread", len(got))
+	}
+}
@@ -0,0 +1,270 @@
+package test
+
+import (
+	"analyze/internal/results"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"math"
+	"runtime"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+type benchmarkCell func(newHashTable Factory, keyGen KeyGen, size int, loadFactor float64, cell string) func(b *testing.B)
+
+var Benchmarks = Registry[benchmarkCell]{
+	{"InsertNoReserve", insertBenchmark(reserveNone)},
+	{"InsertReserve", insertBenchmark(reserveExact)},
+	{"SuccessGet", getBenchmark(lookupSuccess)},
+	{"UnsuccessGet", getBenchmark(lookupMiss)},
+	{"UniformGet", skewedGetBenchmark("Uniform")},
+	{"ZipfGet", skewedGetBenchmark("Zipf")},
+	{"HotSetGet", skewedGetBenchmark("HotSet")},
+	{"LatestGet", skewedGetBenchmark("Latest")},
+	{"Delete", deleteBenchmark},
+	{"Churn", churnBenchmark},
+}
+
+// RunBenchmarks runs the selected benchmarks outside of go test and prints
+// them in the go test -bench format, so the output can be parsed the same way.
+func RunBenchmarks(w io.Writer, operations []string) error {
+	for _, operation := range operations {
+		if _, ok := Benchmarks.Lookup(operation); !ok {
+			return fmt.Errorf("unknown benchmark %q", operation)
+		}
+	}
+
+	// The config lines go test prints, plus what else decides the results.
+	_, err := fmt.Fprintf(w, "goos: %s\ngoarch: %s\ncpu: %s\ncommit: %s\nseed: %d\n", runtime.GOOS, runtime.GOARCH, cpuModel(), gitCommit(), Seed)
+	if err != nil {
+		return err
+	}
+
+	for _, operation := range operations {
+		for method, newHashTable := range Factories.All() {
+			for _, size := range Sizes {
+				for keyKind := range KeyGens.All() {
+					for _, loadFactor := range LoadFactors {
+						cellName := benchmarkName(method, keyKind, loadFactor, size)
+						if !selected(operation, cellName) {
+							continue
+						}
+
+						keyGen := keyGenFor(method, keyKind, cellCapacity(size, loadFactor))
+
+						record := cellRecord(operation, method, keyKind)
+						record.LoadFactor, record.Size = loadFactor, size
+
+						result := testing.Benchmark(Benchmarks.Get(operation)(newHashTable, keyGen, size, loadFactor, operation+"/"+cellName))
+						if result.N == 0 {
+							logAborted([]string{operation, cellName})
+							emit(abortedRecord(record, 0))
+							continue
+						}
+
+						name := "Benchmark" + operation + "/" + cellName
+						if procs := runtime.GOMAXPROCS(0); procs > 1 {
+							name += "-" + strconv.Itoa(procs)
+						}
+
+						if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", name, result.String(), result.MemString()); err != nil {
+							return err
+						}
+
+						emitBenchmark(record, result)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// emitBenchmark writes one record per value of the result, named after its
+// unit like the records parse makes of the printed line.
+func emitBenchmark(record results.Record, result testing.BenchmarkResult) {
+	values := map[string]float64{
+		"ns/op":     float64(result.T.Nanoseconds()) / float64(result.N),
+		"B/op":      float64(result.AllocedBytesPerOp()),
+		"allocs/op": float64(result.AllocsPerOp()),
+	}
+	maps.Copy(values, result.Extra)
+
+	for _, unit := range slices.Sorted(maps.Keys(values)) {
+		record.Metric, record.Value, record.Unit = unit, values[unit], unit
+		emit(record)
+	}
+}
+
+func benchmarkName(method string, keyKind string, loadFactor float64, size int) string {
+	return fmt.Sprintf("%s-%s-%s-%d", method, keyKind, format(loadFactor), size)
+}
+
+func insertBenchmark(strategy reserveStrategy) benchmarkCell {
+	return func(newHashTable Factory, keyGen KeyGen, size int, loadFactor float64, cell string) func(b *testing.B) {
+		return func(b *testing.B) {
+			b.ReportAllocs()
+
+			rng, tableSeed := newCell(cell)
+
+			initCup := 8
+			if strategy == reserveExact {
+				initCup = size
+			}
+
+			// The budget is checked on an untimed table filled with the keys
+			// of the first iteration, so the timed loop only inserts.
+			checkRng, _ := newCell(cell)
+			check := newHashTable(initCup, tableSeed)
+			check.SetLoadFactor(loadFactor)
+			if _, ok := insertAll(check, keyGen(checkRng, size)); !ok {
+				b.Skip(budgetExceeded())
+			}
+
+			for b.Loop() {
+				b.StopTimer()
+				ht := newHashTable(initCup, tableSeed)
+				ht.SetLoadFactor(loadFactor)
+				keysGen := keyGen(rng, size)
+				b.StartTimer()
+
+				for key := range keysGen {
+					ht.Insert(key, key)
+				}
+			}
+
+			nsPerOp := float64(b.Elapsed().Nanoseconds()) / float64(b.N) / float64(size)
+
+			b.ReportMetric(nsPerOp, "ns/insert")
+		}
+	}
+}
+
+func getBenchmark(strategy lookupStrategy) benchmarkCell {
+	return func(newHashTable Factory, keyGen KeyGen, size int, loadFactor float64, cell string) func(b *testing.B) {
+		return func(b *testing.B) {
+			rng, tableSeed := newCell(cell)
+
+			ht := newHashTable(size, tableSeed)
+			ht.SetLoadFactor(loadFactor)
+			insertedKeys, ok := insertAll(ht, keyGen(rng, size))
+			if !ok {
+				b.Skip(budgetExceeded())
+			}
+
+			if strategy == lookupMiss {
+				for i := range insertedKeys {
+					insertedKeys[i] = -i
+				}
+			}
+
+			rng.Shuffle(len(insertedKeys), func(i, j int) {
+				insertedKeys[i], insertedKeys[j] = insertedKeys[j], insertedKeys[i]
+			})
+
+			var idx int
+
+			for b.Loop() {
+				ht.Get(insertedKeys[idx%len(insertedKeys)])
+				idx++
+			}
+		}
+	}
+}
+
+// skewedGetBenchmark looks up inserted keys in the order of a lookup stream,
+// so a few keys take most of the lookups and stay in cache. UniformGet runs
+// the same loop on a uniform stream, as the control of the skewed ones.
+func skewedGetBenchmark(lookups string) benchmarkCell {
+	return func(newHashTable Factory, keyGen KeyGen, size int, loadFactor float64, cell string) func(b *testing.B) {
+		return func(b *testing.B) {
+			rng, tableSeed := newCell(cell)
+
+			ht := newHashTable(size, tableSeed)
+			ht.SetLoadFactor(loadFactor)
+			insertedKeys, ok := insertAll(ht, keyGen(rng, size))
+			if !ok {
+				b.Skip(budgetExceeded())
+			}
+
+			stream := lookupStream(rng, LookupGens.Get(lookups), insertedKeys)
+
+			var idx int
+			for b.Loop() {
+				ht.Get(stream[idx%len(stream)])
+				idx++
+			}
+		}
+	}
+}
+
+func deleteBenchmark(newHashTable Factory, keyGen KeyGen, size int, loadFactor float64, cell string) func(b *testing.B) {
+	return func(b *testing.B) {
+		rng, tableSeed := newCell(cell)
+
+		ht := newHashTable(size, tableSeed)
+		ht.SetLoadFactor(loadFactor)
+		insertedKeys, ok := insertAll(ht, keyGen(rng, size))
+		if !ok {
+			b.Skip(budgetExceeded())
+		}
+
+		rng.Shuffle(len(insertedKeys), func(i, j int) {
+			insertedKeys[i], insertedKeys[j] = insertedKeys[j], insertedKeys[i]
+		})
+
+		var idx int
+		for b.Loop() {
+			b.StopTimer()
+			key := insertedKeys[idx%len(insertedKeys)]
+			idx++
+			b.StartTimer()
+
+			ht.Delete(key)
+
+			b.StopTimer()
+			ht.Insert(key, key)
+			b.StartTimer()
+		}
+	}
+}
+
+// churnBenchmark deletes a random live key and inserts a fresh one in every
+// iteration, so the table keeps its size while tombstones build up.
+func churnBenchmark(newHashTable Factory, keyGen KeyGen, size int, loadFactor float64, cell string) func(b *testing.B) {
+	return func(b *testing.B) {
+		rng, tableSeed := newCell(cell)
+
+		ht := newHashTable(size, tableSeed)
+		ht.SetLoadFactor(loadFactor)
+
+		nextKey, stop := iter.Pull(keyGen(rng, math.MaxInt))
+		defer stop()
+
+		live, ok := insertAll(ht, pullN(nextKey, size))
+		if !ok {
+			b.Skip(budgetExceeded())
+		}
+
+		// The fresh keys and the live keys they replace are drawn before the
+		// timer starts, so the loop only deletes and inserts. That needs b.N
+		// up front, which b.Loop does not give.
+		keys := slices.Collect(pullN(nextKey, b.N))
+		replaced := make([]int, len(keys))
+		for i := range replaced {
+			replaced[i] = rng.Intn(len(live))
+		}
+
+		b.ResetTimer()
+
+		for i, key := range keys {
+			ht.Delete(live[replaced[i]])
+			ht.Insert(key, key)
+			live[replaced[i]] = key
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"analyze/internal/results"
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+)
+
+type recordSink []results.Record
+
+func (s *recordSink) Write(record results.Record) error {
+	*s = append(*s, record)
+	return nil
+}
+
+func (s *recordSink) Close() error {
+	return nil
+}
+
+// Under Multiplicative, HighBitsKey sends every key of a hopscotch table to
+// one home, so that cell goes over the budget while RandomKey does not.
+func TestRunBenchmarksSkipsOverBudgetCell(t *testing.T) {
+	oldSizes, oldLoadFactors, oldSink, oldOnly := Sizes, LoadFactors, Sink, Only
+	oldBenchtime := flag.Lookup("test.benchtime").Value.String()
+	t.Cleanup(func() {
+		Sizes, LoadFactors, Sink, Only = oldSizes, oldLoadFactors, oldSink, oldOnly
+		_ = flag.Set("test.benchtime", oldBenchtime)
+	})
+
+	Sizes, LoadFactors = []int{5000}, []float64{0.8}
+	if err := SetOnly("^InsertNoReserve/Hopscotch-(HighBitsKey|RandomKey)-"); err != nil {
+		t.Fatal(err)
+	}
+	if err := flag.Set("test.benchtime", "1x"); err != nil {
+		t.Fatal(err)
+	}
+
+	var sink recordSink
+	Sink = &sink
+
+	var out bytes.Buffer
+	if err := RunBenchmarks(&out, []string{"InsertNoReserve"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(out.String(), "HighBitsKey") {
+		t.Errorf("over-budget cell printed:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "BenchmarkInsertNoReserve/Hopscotch-RandomKey-0.80-5000") {
+		t.Errorf("cell within the budget not printed:\n%s", out.String())
+	}
+
+	var aborted []string
+	for _, record := range sink {
+		if record.Metric == "aborted" {
+			aborted = append(aborted, record.KeyKind)
+		}
+	}
+	if len(aborted) != 1 || aborted[0] != "HighBitsKey" {
+		t.Errorf("aborted records for %v, want HighBitsKey only", aborted)
+	}
+}
@@ -0,0 +1,163 @@
+package test
+
+import (
+	"analyze/internal/hash_table"
+	"analyze/internal/hash_table/layout"
+	"iter"
+	"math"
+	"path/filepath"
+	"time"
+)
+
+var (
+	// ChurnSize is the capacity of the churned table. The live keys stay at
+	// the load factor of the cell all along.
+	ChurnSize = 1 << 14
+
+	// ChurnRounds is how many times the table capacity worth of keys is
+	// deleted and replaced, ChurnSamples how many points the time series has.
+	ChurnRounds  = 20
+	ChurnSamples = 100
+)
+
+var churnHeader = []string{"round", "probes_per_op", "tombstones", "ns_per_op"}
+
+func RunChurnTest() {
+	for method := range Factories.All() {
+		for keyKind := range KeyGens.All() {
+			for _, loadFactor := range LoadFactors {
+				ChurnTest(method, keyKind, loadFactor)
+			}
+		}
+	}
+}
+
+// ChurnTest keeps a table at a fixed number of live keys while it deletes
+// random keys and inserts fresh ones, and samples the probes per operation,
+// the tombstones and the time per operation as the churn goes on. Unlike the
+// Delete benchmark, which reinserts the key it deleted, this lets tombstones
+// build up where a method leaves them.
+func ChurnTest(method string, keyKind string, loadFactor float64) {
+	if !selected("Churn", method, keyKind) {
+		return
+	}
+
+	cell := []string{"Churn", method, keyKind, format(loadFactor)}
+	rng, tableSeed := newCell(cell...)
+
+	ht := Factories.Get(method)(ChurnSize, tableSeed)
+	ht.SetLoadFactor(1.0)
+
+	capacity := nextPowerOfTwo(ChurnSize)
+	nextKey, stop := iter.Pull(keyGenFor(method, keyKind, capacity)(rng, math.MaxInt))
+	defer stop()
+
+	live, ok := insertAll(ht, pullN(nextKey, int(loadFactor*float64(capacity))))
+
+	record := cellRecord("Churn", method, keyKind)
+	record.LoadFactor, record.Size = loadFactor, ChurnSize
+
+	if !ok || len(live) == 0 {
+		logAborted(cell)
+		emit(abortedRecord(record, 0))
+		return
+	}
+
+	var (
+		metrics  [][]string
+		steps    = ChurnRounds * capacity
+		interval = max(steps/ChurnSamples, 1)
+		total    = ht.Probes()
+	)
+
+	for done := 0; done < steps; {
+		ht.ResetProbes()
+		began := time.Now()
+
+		// ran falls short of n only when the key generator runs dry.
+		n, ran := min(interval, steps-done), 0
+		for ; ran < n; ran++ {
+			key, ok := nextKey()
+			if !ok {
+				break
+			}
+
+			i := rng.Intn(len(live))
+			ht.Delete(live[i])
+			ht.Insert(key, key)
+			live[i] = key
+		}
+		if ran == 0 {
+			break
+		}
+
+		elapsed := time.Since(began)
+		probes := ht.Probes()
+		total += probes
+		done += ran
+
+		var (
+			round      = float64(done) / float64(capacity)
+			ops        = float64(2 * ran)
+			tombstones = countTombstones(ht)
+			nsPerOp    = float64(elapsed.Nanoseconds()) / ops
+		)
+
+		record.Labels = map[string]string{"round": format(round)}
+		record.Metric, record.Value, record.Unit = "probes", float64(probes)/ops, "probes/op"
+		emit(record)
+		record.Metric, record.Value, record.Unit = "tombstones", float64(tombstones), "slots"
+		emit(record)
+		record.Metric, record.Value, record.Unit = "time", nsPerOp, "ns/op"
+		emit(record)
+
+		metrics = append(metrics, getRecord(round, float64(probes)/ops, tombstones, nsPerOp))
+
+		// A table that degrades past the budget is given up after the
+		// samples it got through.
+		if overBudget(total, len(live)+2*done) {
+			logAborted(append(cell, "round "+format(round)))
+			record.Metric = ""
+			aborted := abortedRecord(record, 0)
+			aborted.Labels = record.Labels
+			emit(aborted)
+			break
+		}
+
+		if ran < n {
+			break
+		}
+	}
+
+	saveMetrics(filepath.Join(OutputDir, "Churn", method, format(loadFactor)), keyKind, churnHeader, metrics)
+}
+
+func pullN(next func() (int, bool), n int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for range n {
+			key, ok := next()
+			if !ok || !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// countTombstones is zero for the tables that cannot show their slots.
+func countTombstones(ht hash_table.HashTable) int {
+	inspector, ok := ht.(layout.Inspector)
+	if !ok {
+		return 0
+	}
+
+	var tombstones int
+	for _, slots := range inspector.Snapshot().Tables {
+		for _, slot := range slots {
+			if slot.State == layout.Tombstone {
+				tombstones++
+			}
+		}
+	}
+
+	return tombstones
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"flag"
+	"iter"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// setChurnParams runs a small churn into a temporary directory and collects
+// its records.
+func setChurnParams(t *testing.T, size, rounds, samples int) *recordSink {
+	t.Helper()
+
+	oldSize, oldRounds, oldSamples := ChurnSize, ChurnRounds, ChurnSamples
+	oldDir, oldSink, oldKeyGens := OutputDir, Sink, KeyGens
+	t.Cleanup(func() {
+		ChurnSize, ChurnRounds, ChurnSamples = oldSize, oldRounds, oldSamples
+		OutputDir, Sink, KeyGens = oldDir, oldSink, oldKeyGens
+	})
+
+	ChurnSize, ChurnRounds, ChurnSamples = size, rounds, samples
+	OutputDir = t.TempDir()
+
+	sink := &recordSink{}
+	Sink = sink
+
+	return sink
+}
+
+func churnRounds(sink *recordSink) []string {
+	var rounds []string
+	for _, record := range *sink {
+		if record.Metric == "probes" {
+			rounds = append(rounds, record.Labels["round"])
+		}
+	}
+
+	return rounds
+}
+
+func TestChurnSamples(t *testing.T) {
+	sink := setChurnParams(t, 256, 2, 4)
+
+	ChurnTest("RobinHood", "RandomKey", 0.5)
+
+	want := []string{"0.50", "1.00", "1.50", "2.00"}
+	if got := churnRounds(sink); !slices.Equal(got, want) {
+		t.Errorf("rounds %v, want %v", got, want)
+	}
+}
+
+// A generator that runs dry ends the churn after the operations that ran,
+// and the last sample counts only those.
+func TestChurnKeysRunDry(t *testing.T) {
+	sink := setChurnParams(t, 256, 2, 4)
+
+	// 128 keys fill the table to 0.5, 100 more churn it.
+	KeyGens = append(slices.Clone(KeyGens), Entry[KeyGen]{"FiniteKey", func(_ *rand.Rand, _ int) iter.Seq[int] {
+		return genSequentialKeys(128 + 100)
+	}})
+
+	ChurnTest("RobinHood", "FiniteKey", 0.5)
+
+	want := []string{format(100.0 / 256)}
+	if got := churnRounds(sink); !slices.Equal(got, want) {
+		t.Errorf("rounds %v, want %v", got, want)
+	}
+}
+
+func TestChurnBenchmark(t *testing.T) {
+	old := flag.Lookup("test.benchtime").Value.String()
+	t.Cleanup(func() { _ = flag.Set("test.benchtime", old) })
+	if err := flag.Set("test.benchtime", "1000x"); err != nil {
+		t.Fatal(err)
+	}
+
+	result := testing.Benchmark(churnBenchmark(Factories.Get("RobinHood"), KeyGens.Get("RandomKey"), 1000, 0.8, "Churn/test"))
+	if result.N != 1000 {
+		t.Errorf("ran %d iterations, want 1000", result.N)
+	}
+}
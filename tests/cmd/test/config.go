@@ -0,0 +1,380 @@
+package test
+
+import (
+	"analyze/internal/hash_table"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+)
+
+var Experiments = []string{"collisions", "probes", "layout", "bench", "workload", "replay", "churn", "latency", "resize", "memory"}
+
+// Config is a reproducible experiment definition. Empty fields keep the
+// defaults of the harness.
+type Config struct {
+	Seed        *int64          `json:"seed,omitempty"`
+	Experiments []string        `json:"experiments"`
+	Methods     []MethodConfig  `json:"methods,omitempty"`
+	Hashers     []string        `json:"hashers,omitempty"`
+	Workloads   []string        `json:"workloads,omitempty"`
+	Sizes       []int           `json:"sizes,omitempty"`
+	LoadFactors []float64       `json:"loadFactors,omitempty"`
+	Sweep       *SweepConfig    `json:"sweep,omitempty"`
+	Benchmarks  []string        `json:"benchmarks,omitempty"`
+	Lookups     *LookupConfig   `json:"lookups,omitempty"`
+	Workload    *WorkloadConfig `json:"workload,omitempty"`
+	Traces      []string        `json:"traces,omitempty"`
+	Churn       *ChurnConfig    `json:"churn,omitempty"`
+	Only        string          `json:"only,omitempty"`
+	Repetitions int             `json:"repetitions,omitempty"`
+	Output      OutputConfig    `json:"output"`
+}
+
+type MethodConfig struct {
+	Name    string         `json:"name"`
+	Options map[string]int `json:"options,omitempty"`
+}
+
+type SweepConfig struct {
+	From    float64 `json:"from"`
+	To      float64 `json:"to"`
+	Step    float64 `json:"step"`
+	Size    int     `json:"size,omitempty"`
+	Samples int     `json:"samples,omitempty"`
+}
+
+// LookupConfig sets the distributions of the skewed lookup benchmarks;
+// fields left out keep their defaults.
+type LookupConfig struct {
+	ZipfSkew    float64 `json:"zipfSkew,omitempty"`
+	HotFraction float64 `json:"hotFraction,omitempty"`
+	HotShare    float64 `json:"hotShare,omitempty"`
+}
+
+func (c LookupConfig) params() (float64, float64, float64) {
+	return cmp.Or(c.ZipfSkew, ZipfSkew), cmp.Or(c.HotFraction, HotFraction), cmp.Or(c.HotShare, HotShare)
+}
+
+// WorkloadConfig selects the workload presets and bounds every cell. The
+// duration is written as for time.ParseDuration, e.g. "2s".
+type WorkloadConfig struct {
+	Presets    []string `json:"presets,omitempty"`
+	Operations int      `json:"operations,omitempty"`
+	Duration   string   `json:"duration,omitempty"`
+}
+
+type ChurnConfig struct {
+	Size    int `json:"size,omitempty"`
+	Rounds  int `json:"rounds,omitempty"`
+	Samples int `json:"samples,omitempty"`
+}
+
+type OutputConfig struct {
+	Dir     string   `json:"dir,omitempty"`
+	Formats []string `json:"formats,omitempty"`
+}
+
+func (c SweepConfig) sweep() Sweep {
+	return Sweep{From: c.From, To: c.To, Step: c.Step}
+}
+
+func LoadConfig(path string) (Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer file.Close()
+
+	var config Config
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&config); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err = config.Validate(); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// Validate reports every problem of the config at once.
+func (c Config) Validate() error {
+	var errs []error
+
+	if len(c.Experiments) == 0 {
+		errs = append(errs, errors.New("experiments: at least one is required"))
+	}
+	for _, experiment := range c.Experiments {
+		if !slices.Contains(Experiments, experiment) {
+			errs = append(errs, fmt.Errorf("experiments: unknown experiment %q", experiment))
+		}
+	}
+
+	for _, method := range c.Methods {
+		m, ok := Methods.Lookup(method.Name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("methods: unknown method %q", method.Name))
+			continue
+		}
+		if err := m.validate(method.Name, method.Options); err != nil {
+			errs = append(errs, fmt.Errorf("methods: %w", err))
+		}
+	}
+
+	for _, name := range c.Hashers {
+		if _, ok := Hashers.Lookup(name); !ok {
+			errs = append(errs, fmt.Errorf("hashers: unknown hasher %q", name))
+		}
+	}
+
+	for _, name := range c.Workloads {
+		if _, ok := KeyGens.Lookup(name); !ok {
+			errs = append(errs, fmt.Errorf("workloads: unknown key generator %q", name))
+		}
+	}
+
+	for _, size := range c.Sizes {
+		if size < 1 {
+			errs = append(errs, fmt.Errorf("sizes: %d is not positive", size))
+		}
+	}
+
+	for _, loadFactor := range c.LoadFactors {
+		if loadFactor <= 0 {
+			errs = append(errs, fmt.Errorf("loadFactors: %v is not positive", loadFactor))
+		}
+	}
+
+	if c.Sweep != nil {
+		if err := c.Sweep.sweep().validate(); err != nil {
+			errs = append(errs, fmt.Errorf("sweep: %w", err))
+		}
+		if c.Sweep.Size < 0 || c.Sweep.Samples < 0 {
+			errs = append(errs, errors.New("sweep: size and samples must not be negative"))
+		}
+	}
+
+	for _, name := range c.Benchmarks {
+		if _, ok := Benchmarks.Lookup(name); !ok {
+			errs = append(errs, fmt.Errorf("benchmarks: unknown benchmark %q", name))
+		}
+	}
+
+	if c.Only != "" {
+		if _, err := regexp.Compile(c.Only); err != nil {
+			errs = append(errs, fmt.Errorf("only: %w", err))
+		}
+	}
+
+	if c.Repetitions < 0 {
+		errs = append(errs, fmt.Errorf("repetitions: %d is negative", c.Repetitions))
+	}
+
+	if c.Lookups != nil {
+		if err := checkLookupParams(c.Lookups.params()); err != nil {
+			errs = append(errs, fmt.Errorf("lookups: %w", err))
+		}
+	}
+
+	if slices.Contains(c.Experiments, "replay") && len(c.Traces) == 0 {
+		errs = append(errs, errors.New("traces: the replay experiment needs at least one"))
+	}
+
+	if c.Churn != nil && (c.Churn.Size < 0 || c.Churn.Rounds < 0 || c.Churn.Samples < 0) {
+		errs = append(errs, errors.New("churn: size, rounds and samples must not be negative"))
+	}
+
+	if c.Workload != nil {
+		for _, name := range c.Workload.Presets {
+			if _, ok := Presets.Lookup(name); !ok {
+				errs = append(errs, fmt.Errorf("workload: unknown preset %q", name))
+			}
+		}
+		if c.Workload.Operations < 0 {
+			errs = append(errs, fmt.Errorf("workload: operations %d is negative", c.Workload.Operations))
+		}
+		if c.Workload.Duration != "" {
+			if d, err := time.ParseDuration(c.Workload.Duration); err != nil || d < 0 {
+				errs = append(errs, fmt.Errorf("workload: invalid duration %q", c.Workload.Duration))
+			}
+		}
+	}
+
+	for _, format := range c.Output.Formats {
+		if !slices.Contains(OutputFormats, format) {
+			errs = append(errs, fmt.Errorf("output: unsupported format %q", format))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Apply replaces the harness globals with the values of the config.
+func (c Config) Apply() error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if c.Seed != nil {
+		SetSeed(*c.Seed)
+	}
+
+	if len(c.Methods) > 0 || len(c.Hashers) > 0 {
+		Factories = c.factories()
+	}
+
+	if err := SelectKeyGens(c.Workloads); err != nil {
+		return err
+	}
+
+	if len(c.Sizes) > 0 {
+		Sizes = c.Sizes
+	}
+	if len(c.LoadFactors) > 0 {
+		LoadFactors = c.LoadFactors
+	}
+
+	if c.Sweep != nil {
+		SweepLoadFactors = c.Sweep.sweep().Values()
+		if c.Sweep.Size > 0 {
+			SweepSize = c.Sweep.Size
+		}
+		if c.Sweep.Samples > 0 {
+			SweepSamples = c.Sweep.Samples
+		}
+	}
+
+	if c.Repetitions > 0 {
+		Repetitions = c.Repetitions
+	}
+
+	if c.Lookups != nil {
+		if err := SetLookupParams(c.Lookups.params()); err != nil {
+			return fmt.Errorf("lookups: %w", err)
+		}
+	}
+
+	if c.Workload != nil {
+		if err := SelectPresets(c.Workload.Presets); err != nil {
+			return err
+		}
+		if c.Workload.Operations > 0 {
+			WorkloadOperations = c.Workload.Operations
+		}
+		if c.Workload.Duration != "" {
+			WorkloadDuration, _ = time.ParseDuration(c.Workload.Duration)
+		}
+	}
+
+	if c.Churn != nil {
+		ChurnSize = cmp.Or(c.Churn.Size, ChurnSize)
+		ChurnRounds = cmp.Or(c.Churn.Rounds, ChurnRounds)
+		ChurnSamples = cmp.Or(c.Churn.Samples, ChurnSamples)
+	}
+
+	if c.Output.Dir != "" {
+		OutputDir = c.Output.Dir
+	}
+	if err := SetFormats(c.Output.Formats); err != nil {
+		return err
+	}
+
+	if err := SetOnly(c.Only); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// factories builds one factory per method and hasher. Without hashers every
+// method uses its own.
+func (c Config) factories() Registry[Factory] {
+	methods := c.Methods
+	if len(methods) == 0 {
+		for name := range Methods.All() {
+			methods = append(methods, MethodConfig{Name: name})
+		}
+	}
+
+	var factories Registry[Factory]
+
+	for _, method := range methods {
+		m := Methods.Get(method.Name)
+
+		hashers := c.Hashers
+		if len(hashers) == 0 {
+			hashers = []string{m.DefaultHasher}
+		}
+
+		for _, hasherName := range hashers {
+			hasherFunc := Hashers.Get(hasherName)
+			factories = append(factories, Entry[Factory]{VariantName(method.Name, hasherName), func(c int, seed uint64) hash_table.HashTable {
+				return m.New(c, MethodOptions{Seed: seedFor(m, hasherName, seed), Hasher: hasherFunc, Params: method.Options})
+			}})
+		}
+	}
+
+	return factories
+}
+
+// Run runs the experiments of an applied config.
+func (c Config) Run() error {
+	for _, experiment := range c.Experiments {
+		if err := c.runExperiment(experiment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c Config) runExperiment(experiment string) error {
+	switch experiment {
+	case "collisions":
+		RunCollisionsTest()
+	case "probes":
+		RunProbesTest()
+	case "layout":
+		RunLayoutTest()
+	case "workload":
+		RunWorkloads()
+	case "replay":
+		return RunReplay(c.Traces)
+	case "churn":
+		RunChurnTest()
+	case "latency":
+		RunLatencyTest()
+	case "resize":
+		RunResizeTest()
+	case "memory":
+		RunMemoryTest()
+	case "bench":
+		benchmarks := c.Benchmarks
+		if len(benchmarks) == 0 {
+			benchmarks = Benchmarks.Names()
+		}
+
+		if err := os.MkdirAll(OutputDir, 0o755); err != nil {
+			return err
+		}
+
+		file, err := os.Create(filepath.Join(OutputDir, "bench.txt"))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		return RunBenchmarks(file, benchmarks)
+	}
+
+	return nil
+}
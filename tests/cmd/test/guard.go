@@ -0,0 +1,87 @@
+package test
+
+import (
+	"analyze/internal/hash_table"
+	"analyze/internal/hash_table/resize"
+	"fmt"
+	"iter"
+	"log"
+	"strings"
+)
+
+// MaxProbesPerKey bounds the probes a table may spend per inserted key while
+// the harness fills it. Some key shapes send every key of a weak hasher to the
+// same few slots, which makes each insert linear in the table size; such a
+// cell is given up and reported as aborted instead of running for hours.
+var MaxProbesPerKey = 1000
+
+// MaxSlotsPerKey bounds the capacity a table may grow to per key it holds.
+// Keys that share every bit a weak hasher looks at make hopscotch, cuckoo
+// and double hashing grow on every failed insert while spending few probes;
+// such a cell runs out of memory long before the probe budget notices, so
+// the tables that take a capacity limit are stopped at this one.
+var MaxSlotsPerKey = 64
+
+// budgetCheck is how many inserts pass between two checks of the probe
+// budget. It is also the size below which a table may grow to any
+// MaxSlotsPerKey multiple of it.
+const budgetCheck = 1024
+
+// insertAll inserts every key into ht and returns the inserted keys. It stops
+// early and reports false once ht goes over the probe or the slot budget.
+func insertAll(ht hash_table.HashTable, keys iter.Seq[int]) ([]int, bool) {
+	var (
+		start    = ht.Probes()
+		inserted []int
+	)
+
+	defer limitGrowth(ht, 0)
+
+	for key := range keys {
+		limitGrowth(ht, slotBudget(len(inserted)+1))
+		ht.Insert(key, key)
+		if stopped(ht) {
+			return inserted, false
+		}
+
+		inserted = append(inserted, key)
+
+		if len(inserted)%budgetCheck == 0 && overBudget(ht.Probes()-start, len(inserted)) {
+			return inserted, false
+		}
+	}
+
+	return inserted, true
+}
+
+// slotBudget is the capacity a table that holds keys may grow to.
+func slotBudget(keys int) int {
+	return MaxSlotsPerKey * max(keys, budgetCheck)
+}
+
+// limitGrowth caps the capacity of ht for the inserts that follow, if ht
+// takes a limit. A capacity of 0 lifts the limit.
+func limitGrowth(ht hash_table.HashTable, capacity int) {
+	if limited, ok := ht.(resize.Limited); ok {
+		limited.LimitCapacity(capacity)
+	}
+}
+
+// stopped reports whether ht refused to grow past its limit. It then may have
+// lost keys and is given up.
+func stopped(ht hash_table.HashTable) bool {
+	limited, ok := ht.(resize.Limited)
+	return ok && limited.Stopped()
+}
+
+func overBudget(probes int, inserted int) bool {
+	return probes > MaxProbesPerKey*inserted
+}
+
+func logAborted(cell []string) {
+	log.Printf("%s: aborted, %s", strings.Join(cell, "/"), budgetExceeded())
+}
+
+func budgetExceeded() string {
+	return fmt.Sprintf("more than %d probes or %d slots per inserted key", MaxProbesPerKey, MaxSlotsPerKey)
+}
@@ -0,0 +1,48 @@
+package test
+
+import (
+	"analyze/internal/hash_table/hopscotch"
+	"analyze/internal/hash_table/resize"
+	"testing"
+)
+
+// Keys i<<40 share their home in every hopscotch table that fits in memory,
+// so the table doubles on every insert past its neighbourhood.
+func TestInsertAllStopsRunawayGrowth(t *testing.T) {
+	ht := hopscotch.New(8)
+
+	keys := func(yield func(int) bool) {
+		for i := 0; i < 2000; i++ {
+			if !yield(i << 40) {
+				return
+			}
+		}
+	}
+
+	inserted, ok := insertAll(ht, keys)
+	if ok {
+		t.Fatalf("inserted %d keys into a table of %d slots", len(inserted), ht.Capacity())
+	}
+	if len(inserted) >= 2000 {
+		t.Errorf("inserted all %d keys", len(inserted))
+	}
+}
+
+func TestInsertAllWithinBudget(t *testing.T) {
+	ht := hopscotch.New(8)
+
+	inserted, ok := insertAll(ht, genSequentialKeys(10000))
+	if !ok || len(inserted) != 10000 || ht.Size() != 10000 {
+		t.Errorf("ok %v, inserted %d, size %d", ok, len(inserted), ht.Size())
+	}
+
+	// A new observer replaces the guard's one.
+	var resizes int
+	ht.OnResize(func(resize.Event) { resizes++ })
+	for i := 10000; i < 100000; i++ {
+		ht.Insert(i, i)
+	}
+	if resizes == 0 {
+		t.Error("no resize reported after the guard")
+	}
+}
@@ -0,0 +1,177 @@
+package test
+
+import (
+	"analyze/internal/hdr"
+	"analyze/internal/results"
+	"analyze/internal/workload"
+	"path/filepath"
+	"time"
+)
+
+// latencyBatch is how many lookups are timed together. A lookup takes about
+// as long as reading the clock, so timing each one would mostly measure the
+// clock; inserts are timed one by one to catch every resize.
+const latencyBatch = 16
+
+var latencyHeader = []string{"operations", "mean_ns", "p50_ns", "p90_ns", "p99_ns", "p999_ns", "max_ns"}
+
+// latency is a latency summary in nanoseconds.
+type latency struct {
+	count                            int
+	mean, p50, p90, p99, p999, worst float64
+}
+
+func histogramLatency(h *hdr.Histogram) latency {
+	return latency{
+		count: int(h.Count()),
+		mean:  h.Mean(),
+		p50:   float64(h.Quantile(0.5)),
+		p90:   float64(h.Quantile(0.9)),
+		p99:   float64(h.Quantile(0.99)),
+		p999:  float64(h.Quantile(0.999)),
+		worst: float64(h.Max()),
+	}
+}
+
+func workloadLatency(l workload.Latency) latency {
+	return latency{
+		count: l.Count,
+		mean:  nanoseconds(l.Mean),
+		p50:   nanoseconds(l.P50),
+		p90:   nanoseconds(l.P90),
+		p99:   nanoseconds(l.P99),
+		p999:  nanoseconds(l.P999),
+		worst: nanoseconds(l.Max),
+	}
+}
+
+func (l latency) columns() []string {
+	return getRecord(l.count, l.mean, l.p50, l.p90, l.p99, l.p999, l.worst)
+}
+
+func emitLatency(record results.Record, l latency) {
+	record.Metric, record.Value, record.Unit = "operations", float64(l.count), "count"
+	emit(record)
+
+	record.Unit = "ns"
+	for _, q := range []struct {
+		metric string
+		value  float64
+	}{
+		{"latency_mean", l.mean},
+		{"latency_p50", l.p50},
+		{"latency_p90", l.p90},
+		{"latency_p99", l.p99},
+		{"latency_p999", l.p999},
+		{"latency_max", l.worst},
+	} {
+		record.Metric, record.Value = q.metric, q.value
+		emit(record)
+	}
+}
+
+func nanoseconds(d time.Duration) float64 {
+	return float64(d.Nanoseconds())
+}
+
+func RunLatencyTest() {
+	for method := range Factories.All() {
+		for keyKind := range KeyGens.All() {
+			for _, loadFactor := range LoadFactors {
+				LatencyTest(method, keyKind, loadFactor)
+			}
+		}
+	}
+}
+
+// LatencyTest grows a table from its smallest capacity to every size, timing
+// each insert, then times successful lookups in batches. The percentiles
+// show the resize spikes that the mean time per insert of the benchmarks
+// averages away.
+func LatencyTest(method string, keyKind string, loadFactor float64) {
+	if !selected("Latency", method, keyKind) {
+		return
+	}
+
+	var insertMetrics, getMetrics [][]string
+
+	record := cellRecord("Latency", method, keyKind)
+	record.LoadFactor = loadFactor
+
+	for _, size := range Sizes {
+		cell := []string{"Latency", method, keyKind, format(loadFactor), format(size)}
+		rng, tableSeed := newCell(cell...)
+
+		ht := Factories.Get(method)(8, tableSeed)
+		ht.SetLoadFactor(loadFactor)
+
+		var (
+			inserts = hdr.New()
+			keys    = make([]int, 0, size)
+			aborted bool
+		)
+
+		for key := range keyGenFor(method, keyKind, cellCapacity(size, loadFactor))(rng, size) {
+			limitGrowth(ht, slotBudget(len(keys)+1))
+
+			start := time.Now()
+			ht.Insert(key, key)
+			inserts.Record(int64(time.Since(start)))
+
+			keys = append(keys, key)
+			if stopped(ht) || len(keys)%budgetCheck == 0 && overBudget(ht.Probes(), len(keys)) {
+				aborted = true
+				break
+			}
+		}
+		limitGrowth(ht, 0)
+
+		record.Size = size
+
+		if aborted {
+			logAborted(cell)
+			emit(abortedRecord(record, 0))
+			continue
+		}
+
+		rng.Shuffle(len(keys), func(i, j int) {
+			keys[i], keys[j] = keys[j], keys[i]
+		})
+
+		gets := hdr.New()
+		for batch := range chunks(keys, latencyBatch) {
+			start := time.Now()
+			for _, key := range batch {
+				ht.Get(key)
+			}
+			gets.RecordN(int64(time.Since(start))/int64(len(batch)), uint64(len(batch)))
+		}
+
+		insertLatency, getLatency := histogramLatency(inserts), histogramLatency(gets)
+
+		record.Labels = map[string]string{"op": "insert"}
+		emitLatency(record, insertLatency)
+		record.Labels = map[string]string{"op": "get"}
+		emitLatency(record, getLatency)
+
+		insertMetrics = append(insertMetrics, append(getRecord(size), insertLatency.columns()...))
+		getMetrics = append(getMetrics, append(getRecord(size), getLatency.columns()...))
+	}
+
+	header := append([]string{"size"}, latencyHeader...)
+	lfString := format(loadFactor)
+	saveMetrics(filepath.Join(OutputDir, "Latency", "Insert", method, lfString), keyKind, header, insertMetrics)
+	saveMetrics(filepath.Join(OutputDir, "Latency", "Get", method, lfString), keyKind, header, getMetrics)
+}
+
+func chunks(keys []int, n int) func(yield func([]int) bool) {
+	return func(yield func([]int) bool) {
+		for len(keys) > 0 {
+			batch := keys[:min(n, len(keys))]
+			keys = keys[len(batch):]
+			if !yield(batch) {
+				return
+			}
+		}
+	}
+}
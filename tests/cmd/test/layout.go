@@ -0,0 +1,174 @@
+package test
+
+import (
+	"analyze/internal/analysis"
+	"analyze/internal/hash_table/layout"
+	"path/filepath"
+)
+
+type bucketInspector interface {
+	BucketLengths() []int
+}
+
+func RunLayoutTest() {
+	for method, newHashTable := range Factories.All() {
+		ht := newHashTable(8, 0)
+		_, isOpen := ht.(layout.Inspector)
+		_, isChained := ht.(bucketInspector)
+
+		for keyKind := range KeyGens.All() {
+			for _, loadFactor := range LoadFactors {
+				if isOpen {
+					LayoutTest(method, keyKind, loadFactor)
+				}
+
+				if isChained {
+					ChainLengthTest(method, keyKind, loadFactor)
+				}
+			}
+		}
+	}
+}
+
+// LayoutTest fills a table up to loadFactor, deletes a share of the keys to
+// leave tombstones behind and writes the occupancy of every array and the
+// cluster, displacement, empty-run and tombstone distributions. Cuckoo
+// hashing places keys along no probe sequence, so it only gets the occupancy
+// of its two tables and, as displacement, the table every key sits in.
+func LayoutTest(method string, keyKind string, loadFactor float64) {
+	if !selected("Layout", method, keyKind) {
+		return
+	}
+
+	var (
+		size        = 5000
+		deleteRatio = 0.1
+	)
+
+	rng, tableSeed := newCell("Layout", method, keyKind, format(loadFactor))
+	ht, insertedKeys, ok := fillTable(rng, tableSeed, method, keyKind, loadFactor, size)
+	if !ok {
+		logAborted([]string{"Layout", method, keyKind, format(loadFactor)})
+		emit(abortedRecord(cellRecord("Layout", method, keyKind), 0))
+		return
+	}
+
+	rng.Shuffle(len(insertedKeys), func(i, j int) {
+		insertedKeys[i], insertedKeys[j] = insertedKeys[j], insertedKeys[i]
+	})
+
+	for _, key := range insertedKeys[:int(deleteRatio*float64(len(insertedKeys)))] {
+		ht.Delete(key)
+	}
+
+	snapshot := ht.(layout.Inspector).Snapshot()
+	report := analysis.Analyze(snapshot)
+
+	record := cellRecord("Layout", method, keyKind)
+	record.LoadFactor, record.Size = loadFactor, size
+
+	var occupancyMetrics [][]string
+	record.Metric, record.Unit = "occupancy", ""
+	for table, occupancy := range report.Occupancy {
+		occupancyMetrics = append(occupancyMetrics, getRecord(table, len(snapshot.Tables[table]), occupancy))
+
+		record.Labels = map[string]string{"table": format(table)}
+		record.Value = occupancy
+		emit(record)
+	}
+
+	record.Unit = "count"
+	record.Metric = "displacements"
+	emitHistogram(record, "distance", report.Displacements)
+
+	lfString := format(loadFactor)
+	saveMetrics(filepath.Join(OutputDir, "Occupancy", method, lfString), keyKind, []string{"table", "slots", "occupancy"}, occupancyMetrics)
+	saveMetrics(filepath.Join(OutputDir, "Displacement", method, lfString), keyKind, []string{"distance", "count"}, histogramRecords(report.Displacements))
+
+	if snapshot.Scattered {
+		return
+	}
+
+	record.Metric = "clusters"
+	emitHistogram(record, "length", report.Clusters)
+	record.Metric = "empty_runs"
+	emitHistogram(record, "length", report.EmptyRuns)
+
+	record.Metric, record.Value, record.Unit = "tombstones", float64(len(report.Tombstones)), "slots"
+	emit(record)
+
+	var tombstoneMetrics [][]string
+	for _, position := range report.Tombstones {
+		tombstoneMetrics = append(tombstoneMetrics, getRecord(position))
+	}
+
+	saveMetrics(filepath.Join(OutputDir, "ClusterLength", method, lfString), keyKind, []string{"length", "count"}, histogramRecords(report.Clusters))
+	saveMetrics(filepath.Join(OutputDir, "EmptyRun", method, lfString), keyKind, []string{"length", "count"}, histogramRecords(report.EmptyRuns))
+	saveMetrics(filepath.Join(OutputDir, "Tombstone", method, lfString), keyKind, []string{"position"}, tombstoneMetrics)
+}
+
+func histogramRecords(histogram analysis.Histogram) [][]string {
+	var records [][]string
+
+	for _, length := range histogram.Keys() {
+		records = append(records, getRecord(length, histogram[length]))
+	}
+
+	return records
+}
+
+// ChainLengthTest writes, for every size, the bucket length histogram next to
+// the Poisson expectation and a summary row with the chi-squared statistic.
+func ChainLengthTest(method string, keyKind string, loadFactor float64) {
+	if !selected("ChainLength", method, keyKind) {
+		return
+	}
+
+	var (
+		histogramMetrics [][]string
+		summaryMetrics   [][]string
+	)
+
+	for _, size := range Sizes {
+		rng, tableSeed := newCell("ChainLength", method, keyKind, format(loadFactor), format(size))
+
+		ht := Factories.Get(method)(size, tableSeed)
+		ht.SetLoadFactor(loadFactor)
+		keysGen := keyGenFor(method, keyKind, cellCapacity(size, loadFactor))(rng, size)
+
+		record := cellRecord("ChainLength", method, keyKind)
+		record.LoadFactor, record.Size = loadFactor, size
+
+		if _, ok := insertAll(ht, keysGen); !ok {
+			logAborted([]string{"ChainLength", method, keyKind, format(loadFactor), format(size)})
+			emit(abortedRecord(record, 0))
+			continue
+		}
+
+		report := analysis.AnalyzeChains(ht.(bucketInspector).BucketLengths())
+
+		for _, bin := range report.Bins {
+			histogramMetrics = append(histogramMetrics, getRecord(size, bin.Length, bin.Observed, bin.Expected))
+
+			record.Labels = map[string]string{"length": format(bin.Length)}
+			record.Metric, record.Value, record.Unit = "observed_buckets", float64(bin.Observed), "buckets"
+			emit(record)
+			record.Metric, record.Value = "expected_buckets", bin.Expected
+			emit(record)
+		}
+
+		record.Labels = nil
+		record.Metric, record.Value, record.Unit = "chi_squared", report.ChiSquared, ""
+		emit(record)
+		record.Metric, record.Value, record.Unit = "max_length", float64(report.MaxLength), "entries"
+		emit(record)
+
+		summaryMetrics = append(summaryMetrics, getRecord(
+			size, report.LoadFactor, report.Buckets, report.Empty, report.MaxLength, report.ChiSquared, report.DegreesOfFreedom,
+		))
+	}
+
+	lfString := format(loadFactor)
+	saveMetrics(filepath.Join(OutputDir, "ChainLength", method, lfString), keyKind, []string{"size", "length", "observed", "expected"}, histogramMetrics)
+	saveMetrics(filepath.Join(OutputDir, "ChainStats", method, lfString), keyKind, []string{"size", "load_factor", "buckets", "empty", "max_length", "chi_squared", "degrees_of_freedom"}, summaryMetrics)
+}
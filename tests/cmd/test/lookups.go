@@ -0,0 +1,113 @@
+package test
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// LookupGen returns a sampler of the keys a lookup stream asks for, as
+// indices into n keys in insertion order.
+type LookupGen func(r *rand.Rand, n int) func() int
+
+var (
+	// ZipfSkew is the exponent s of the Zipf and Latest streams; the k-th
+	// most popular key is asked for proportionally to 1/(k+1)^s.
+	ZipfSkew = 1.1
+
+	// HotFraction of the keys get HotShare of the lookups of the HotSet
+	// stream.
+	HotFraction = 0.2
+	HotShare    = 0.8
+
+	LookupGens = Registry[LookupGen]{
+		{"Uniform", uniformLookups},
+		{"Zipf", zipfLookups},
+		{"HotSet", hotSetLookups},
+		{"Latest", latestLookups},
+	}
+)
+
+// A benchmark cycles through a precomputed stream, so drawing from the
+// distribution is not part of the timing. The stream is as long as the table
+// has keys, within these bounds, so cycling does not shrink the set of keys
+// that is asked for.
+const (
+	minLookupSamples = 1 << 16
+	maxLookupSamples = 1 << 22
+)
+
+func SetLookupParams(zipfSkew, hotFraction, hotShare float64) error {
+	if err := checkLookupParams(zipfSkew, hotFraction, hotShare); err != nil {
+		return err
+	}
+
+	ZipfSkew, HotFraction, HotShare = zipfSkew, hotFraction, hotShare
+
+	return nil
+}
+
+func checkLookupParams(zipfSkew, hotFraction, hotShare float64) error {
+	switch {
+	case zipfSkew <= 1:
+		return errors.New("zipf skew must be above 1")
+	case hotFraction <= 0 || hotFraction > 1:
+		return errors.New("hot fraction must be in (0, 1]")
+	case hotShare < 0 || hotShare > 1:
+		return errors.New("hot share must be in [0, 1]")
+	}
+
+	return nil
+}
+
+func uniformLookups(r *rand.Rand, n int) func() int {
+	return func() int { return r.Intn(n) }
+}
+
+// zipfLookups ranks the keys in a random order, so popularity has nothing to
+// do with when a key was inserted.
+func zipfLookups(r *rand.Rand, n int) func() int {
+	if n == 1 {
+		return func() int { return 0 }
+	}
+
+	ranks := r.Perm(n)
+	zipf := rand.NewZipf(r, ZipfSkew, 1, uint64(n-1))
+
+	return func() int { return ranks[zipf.Uint64()] }
+}
+
+func hotSetLookups(r *rand.Rand, n int) func() int {
+	keys := r.Perm(n)
+	hot := max(1, int(HotFraction*float64(n)))
+
+	return func() int {
+		if hot == n || r.Float64() < HotShare {
+			return keys[r.Intn(hot)]
+		}
+
+		return keys[hot+r.Intn(n-hot)]
+	}
+}
+
+// latestLookups favours the keys inserted last, like reads of fresh data.
+func latestLookups(r *rand.Rand, n int) func() int {
+	if n == 1 {
+		return func() int { return 0 }
+	}
+
+	zipf := rand.NewZipf(r, ZipfSkew, 1, uint64(n-1))
+
+	return func() int { return n - 1 - int(zipf.Uint64()) }
+}
+
+// lookupStream draws the keys of a lookup stream up front.
+func lookupStream(r *rand.Rand, gen LookupGen, keys []int) []int {
+	next := gen(r, len(keys))
+
+	stream := make([]int, min(max(len(keys), minLookupSamples), maxLookupSamples))
+	for i := range stream {
+		stream[i] = keys[next()]
+	}
+
+	return stream
+}
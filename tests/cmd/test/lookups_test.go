@@ -0,0 +1,138 @@
+package test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestLookupGensInBounds(t *testing.T) {
+	for name, gen := range LookupGens.All() {
+		for _, n := range []int{1, 2, 10, 1000} {
+			rng := rand.New(rand.NewSource(1))
+			next := gen(rng, n)
+
+			for range 10_000 {
+				if i := next(); i < 0 || i >= n {
+					t.Fatalf("%s with n=%d: index %d out of range", name, n, i)
+				}
+			}
+		}
+	}
+}
+
+func TestHotSetShare(t *testing.T) {
+	tests := []struct {
+		name         string
+		n            int
+		hotFraction  float64
+		hotShare     float64
+		wantHotShare float64
+	}{
+		{"default", 100, 0.2, 0.8, 0.8},
+		{"all hot", 100, 1, 0.8, 1},
+		{"single key", 1, 0.2, 0.8, 1},
+		{"even share", 100, 0.2, 0.5, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLookupParams(t, ZipfSkew, tt.hotFraction, tt.hotShare)
+
+			const samples = 200_000
+			next := hotSetLookups(rand.New(rand.NewSource(1)), tt.n)
+
+			counts := make([]int, tt.n)
+			for range samples {
+				counts[next()]++
+			}
+
+			// The hot keys are the most asked for, whichever they are.
+			slices.SortFunc(counts, func(a, b int) int { return b - a })
+			hot := max(1, int(tt.hotFraction*float64(tt.n)))
+
+			var hotCount int
+			for _, c := range counts[:hot] {
+				hotCount += c
+			}
+
+			if share := float64(hotCount) / samples; share < tt.wantHotShare-0.01 || share > tt.wantHotShare+0.01 {
+				t.Errorf("hot keys got %.3f of the lookups, want %.3f", share, tt.wantHotShare)
+			}
+		})
+	}
+}
+
+func TestSkewedLookupsFavourFewKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		gen  LookupGen
+		// top is the index that must be asked for most, -1 for any.
+		top int
+	}{
+		{"Zipf", zipfLookups, -1},
+		{"Latest", latestLookups, 999},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := tt.gen(rand.New(rand.NewSource(1)), 1000)
+
+			counts := make([]int, 1000)
+			for range 100_000 {
+				counts[next()]++
+			}
+
+			most := slices.Index(counts, slices.Max(counts))
+			if tt.top >= 0 && most != tt.top {
+				t.Errorf("most asked for index %d, want %d", most, tt.top)
+			}
+			if share := float64(counts[most]) / 100_000; share < 0.1 {
+				t.Errorf("most asked for index got only %.3f of the lookups", share)
+			}
+		})
+	}
+}
+
+func TestLookupStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    int
+		wantLen int
+	}{
+		{"single key", 1, minLookupSamples},
+		{"small", 100, minLookupSamples},
+		{"large", minLookupSamples + 5, minLookupSamples + 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := make([]int, tt.keys)
+			for i := range keys {
+				keys[i] = -10 * i
+			}
+
+			stream := lookupStream(rand.New(rand.NewSource(1)), hotSetLookups, keys)
+
+			if len(stream) != tt.wantLen {
+				t.Errorf("stream of %d lookups, want %d", len(stream), tt.wantLen)
+			}
+			for _, key := range stream {
+				if key > 0 || key%10 != 0 || -key/10 >= tt.keys {
+					t.Fatalf("stream asks for %d, which is not one of the keys", key)
+				}
+			}
+		})
+	}
+}
+
+func setLookupParams(t *testing.T, zipfSkew, hotFraction, hotShare float64) {
+	t.Helper()
+
+	old := []float64{ZipfSkew, HotFraction, HotShare}
+	t.Cleanup(func() { ZipfSkew, HotFraction, HotShare = old[0], old[1], old[2] })
+
+	if err := SetLookupParams(zipfSkew, hotFraction, hotShare); err != nil {
+		t.Fatal(err)
+	}
+}
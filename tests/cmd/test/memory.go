@@ -0,0 +1,69 @@
+package test
+
+import (
+	"analyze/internal/hash_table/memory"
+	"log"
+	"path/filepath"
+)
+
+var memoryHeader = []string{"size", "capacity", "bytes", "bytes_per_entry"}
+
+func RunMemoryTest() {
+	for method := range Factories.All() {
+		for keyKind := range KeyGens.All() {
+			for _, loadFactor := range LoadFactors {
+				MemoryTest(method, keyKind, loadFactor)
+			}
+		}
+	}
+}
+
+// MemoryTest grows a table from its smallest capacity to every size and
+// writes the bytes it holds per entry, as the table counts them. Unlike the
+// allocations of -benchmem, this leaves out the garbage of earlier resizes
+// and counts only what the table keeps.
+func MemoryTest(method string, keyKind string, loadFactor float64) {
+	if !selected("Memory", method, keyKind) {
+		return
+	}
+
+	var metrics [][]string
+
+	record := cellRecord("Memory", method, keyKind)
+	record.LoadFactor = loadFactor
+
+	for _, size := range Sizes {
+		cell := []string{"Memory", method, keyKind, format(loadFactor), format(size)}
+		rng, tableSeed := newCell(cell...)
+
+		ht := Factories.Get(method)(8, tableSeed)
+		ht.SetLoadFactor(loadFactor)
+
+		reporter, ok := ht.(memory.Reporter)
+		if !ok {
+			log.Fatalf("%s: %T does not report its memory usage", method, ht)
+		}
+
+		record.Size = size
+
+		keys, ok := insertAll(ht, keyGenFor(method, keyKind, cellCapacity(size, loadFactor))(rng, size))
+		if !ok || len(keys) == 0 {
+			logAborted(cell)
+			emit(abortedRecord(record, 0))
+			continue
+		}
+
+		bytes := reporter.MemoryUsage()
+		perEntry := float64(bytes) / float64(ht.Size())
+
+		record.Metric, record.Value, record.Unit = "memory", float64(bytes), "bytes"
+		emit(record)
+		record.Metric, record.Value, record.Unit = "memory_per_entry", perEntry, "bytes/entry"
+		emit(record)
+		record.Metric = ""
+
+		metrics = append(metrics, getRecord(size, ht.Capacity(), bytes, perEntry))
+	}
+
+	saveMetrics(filepath.Join(OutputDir, "Memory", method, format(loadFactor)), keyKind, memoryHeader, metrics)
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"analyze/internal/hash_table"
+	"analyze/internal/hash_table/chain"
+	"analyze/internal/hash_table/cuckoo"
+	double "analyze/internal/hash_table/double_hash"
+	"analyze/internal/hash_table/hasher"
+	"analyze/internal/hash_table/hopscotch"
+	robinhood "analyze/internal/hash_table/robin_hood"
+	"analyze/internal/theory"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type MethodOptions struct {
+	Seed   uint64
+	Hasher hasher.Func
+	Params map[string]int
+}
+
+// Method describes how to build a table with non-default options. Params
+// lists the method-specific integer options New understands.
+//
+// SeedsSalts marks methods that draw salts from the seed, which they need
+// whatever the hasher.
+type Method struct {
+	DefaultHasher string
+	Params        []string
+	SeedsSalts    bool
+	New           func(cap int, opts MethodOptions) hash_table.HashTable
+}
+
+var (
+	Hashers = Registry[hasher.Func]{
+		{"Multiplicative", hasher.Multiplicative},
+		{"SplitMix", hasher.SplitMix},
+	}
+
+	// mixingHashers are the hashers a seed turns into independent functions.
+	mixingHashers = []string{"SplitMix"}
+
+	Methods = Registry[Method]{
+		{"Chain", Method{
+			DefaultHasher: "Multiplicative",
+			New: func(c int, o MethodOptions) hash_table.HashTable {
+				return chain.New(c, chain.WithSeed(o.Seed), chain.WithHasher(o.Hasher))
+			},
+		}},
+		{"Cuckoo", Method{
+			DefaultHasher: "SplitMix",
+			Params:        []string{"maxKicks", "maxRehashes"},
+			SeedsSalts:    true,
+			New: func(c int, o MethodOptions) hash_table.HashTable {
+				opts := []cuckoo.Option{cuckoo.WithSeed(o.Seed), cuckoo.WithHasher(o.Hasher)}
+				if v, ok := o.Params["maxKicks"]; ok {
+					opts = append(opts, cuckoo.WithMaxKicks(v))
+				}
+				if v, ok := o.Params["maxRehashes"]; ok {
+					opts = append(opts, cuckoo.WithMaxRehashes(v))
+				}
+
+				return cuckoo.New(c, opts...)
+			},
+		}},
+		{"Double", Method{
+			DefaultHasher: "Multiplicative",
+			New: func(c int, o MethodOptions) hash_table.HashTable {
+				return double.New(c, double.WithSeed(o.Seed), double.WithHasher(o.Hasher))
+			},
+		}},
+		{"Hopscotch", Method{
+			DefaultHasher: "Multiplicative",
+			New: func(c int, o MethodOptions) hash_table.HashTable {
+				return hopscotch.New(c, hopscotch.WithSeed(o.Seed), hopscotch.WithHasher(o.Hasher))
+			},
+		}},
+		{"RobinHood", Method{
+			DefaultHasher: "Multiplicative",
+			New: func(c int, o MethodOptions) hash_table.HashTable {
+				return robinhood.New(c, robinhood.WithSeed(o.Seed), robinhood.WithHasher(o.Hasher))
+			},
+		}},
+	}
+)
+
+// VariantName names a method built with a hasher. The method's own hasher
+// keeps the plain name, so default results stay where they always were;
+// other hashers are appended after a dot, e.g. "Chain.SplitMix".
+func VariantName(method string, hasherName string) string {
+	if hasherName == "" || Methods.Get(method).DefaultHasher == hasherName {
+		return method
+	}
+
+	return method + "." + hasherName
+}
+
+// seedFor is the seed a table of the method gets under the hasher. Seeding
+// Multiplicative would only move the keys around, so its tables get 0 and
+// hash as they always did.
+func seedFor(m Method, hasherName string, seed uint64) uint64 {
+	if m.SeedsSalts || slices.Contains(mixingHashers, hasherName) {
+		return seed
+	}
+
+	return 0
+}
+
+func baseMethod(variant string) string {
+	method, _, _ := strings.Cut(variant, ".")
+	return method
+}
+
+// variantHasher is the hasher a variant is built with: the one after the dot,
+// or the method's default one.
+func variantHasher(variant string) string {
+	method, hasherName, ok := strings.Cut(variant, ".")
+	if !ok {
+		hasherName = Methods.Get(method).DefaultHasher
+	}
+
+	return hasherName
+}
+
+func modelFor(variant string) theory.Model {
+	return Models[baseMethod(variant)]
+}
+
+func (m Method) validate(name string, params map[string]int) error {
+	for param, value := range params {
+		if !slices.Contains(m.Params, param) {
+			return fmt.Errorf("method %s: unknown option %q", name, param)
+		}
+		if value < 1 {
+			return fmt.Errorf("method %s: option %s must be positive, got %d", name, param, value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package test
+
+import "testing"
+
+func TestSeedFor(t *testing.T) {
+	tests := []struct {
+		method, hasher string
+		want           uint64
+	}{
+		{"Chain", "Multiplicative", 0},
+		{"RobinHood", "Multiplicative", 0},
+		{"Chain", "SplitMix", 42},
+		{"Cuckoo", "SplitMix", 42},
+		{"Cuckoo", "Multiplicative", 42},
+	}
+
+	for _, tt := range tests {
+		if got := seedFor(Methods.Get(tt.method), tt.hasher, 42); got != tt.want {
+			t.Errorf("seedFor(%s, %s) = %d, want %d", tt.method, tt.hasher, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package test
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var summaryHeader = []string{"std", "min", "max", "ci_low", "ci_high"}
+
+// Metadata is written next to every result file as <name>.meta.json, so a
+// result file tells on its own how it was produced.
+type Metadata struct {
+	File       string    `json:"file"`
+	CreatedAt  time.Time `json:"createdAt"`
+	GoVersion  string    `json:"goVersion"`
+	OS         string    `json:"os"`
+	Arch       string    `json:"arch"`
+	CPU        string    `json:"cpu"`
+	NumCPU     int       `json:"numCpu"`
+	GOMAXPROCS int       `json:"gomaxprocs"`
+	Commit     string    `json:"commit"`
+	Seed       int64     `json:"seed"`
+	Config     Settings  `json:"config"`
+}
+
+// Settings is the part of the harness state that decides what a run measures.
+type Settings struct {
+	Methods          []string  `json:"methods"`
+	KeyGens          []string  `json:"keyGens"`
+	Sizes            []int     `json:"sizes"`
+	LoadFactors      []float64 `json:"loadFactors"`
+	SweepLoadFactors []float64 `json:"sweepLoadFactors"`
+	SweepSize        int       `json:"sweepSize"`
+	SweepSamples     int       `json:"sweepSamples"`
+	Repetitions      int       `json:"repetitions"`
+	ZipfSkew         float64   `json:"zipfSkew"`
+	HotFraction      float64   `json:"hotFraction"`
+	HotShare         float64   `json:"hotShare"`
+	Presets          []string  `json:"presets"`
+	WorkloadOps      int       `json:"workloadOperations"`
+	WorkloadDuration string    `json:"workloadDuration,omitempty"`
+	ChurnSize        int       `json:"churnSize"`
+	ChurnRounds      int       `json:"churnRounds"`
+	ChurnSamples     int       `json:"churnSamples"`
+	Only             string    `json:"only,omitempty"`
+}
+
+func CurrentSettings() Settings {
+	settings := Settings{
+		Methods:          Factories.Names(),
+		KeyGens:          KeyGens.Names(),
+		Sizes:            Sizes,
+		LoadFactors:      LoadFactors,
+		SweepLoadFactors: SweepLoadFactors,
+		SweepSize:        SweepSize,
+		SweepSamples:     SweepSamples,
+		Repetitions:      Repetitions,
+		ZipfSkew:         ZipfSkew,
+		HotFraction:      HotFraction,
+		HotShare:         HotShare,
+		Presets:          Presets.Names(),
+		WorkloadOps:      WorkloadOperations,
+		ChurnSize:        ChurnSize,
+		ChurnRounds:      ChurnRounds,
+		ChurnSamples:     ChurnSamples,
+	}
+
+	if WorkloadDuration > 0 {
+		settings.WorkloadDuration = WorkloadDuration.String()
+	}
+
+	if Only != nil {
+		settings.Only = Only.String()
+	}
+
+	return settings
+}
+
+func NewMetadata(file string) Metadata {
+	return Metadata{
+		File:       file,
+		CreatedAt:  time.Now().UTC(),
+		GoVersion:  runtime.Version(),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		CPU:        cpuModel(),
+		NumCPU:     runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Commit:     gitCommit(),
+		Seed:       Seed,
+		Config:     CurrentSettings(),
+	}
+}
+
+func saveMetrics(dir, keyKind string, header []string, metrics [][]string) {
+	if !writeCSV() {
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Fatalf("failed to create directory %s: %v", dir, err)
+	}
+
+	filePath := filepath.Join(dir, keyKind+".csv")
+
+	err := writeAtomically(filePath, func(file *os.File) error {
+		writer := csv.NewWriter(file)
+
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(metrics); err != nil {
+			return err
+		}
+
+		return writer.Error()
+	})
+	if err != nil {
+		log.Fatalf("failed to write CSV %s: %v", filePath, err)
+	}
+
+	saveMetadata(filePath)
+}
+
+func saveMetadata(filePath string) {
+	metaPath := filePath + ".meta.json"
+
+	err := writeAtomically(metaPath, func(file *os.File) error {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(NewMetadata(filepath.Base(filePath)))
+	})
+	if err != nil {
+		log.Fatalf("failed to write metadata %s: %v", metaPath, err)
+	}
+}
+
+// writeAtomically writes into a temporary file of the same directory and
+// renames it over path, so readers never see a half-written or stale file.
+func writeAtomically(path string, write func(file *os.File) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err = write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func getRecord(metrics ...any) []string {
+	var record []string
+
+	for _, metric := range metrics {
+		record = append(record, format(metric))
+	}
+
+	return record
+}
+
+func format(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	default:
+		log.Fatalf("unsupported metric type: %T", v)
+		return ""
+	}
+}
+
+var cpuModel = sync.OnceValue(func() string {
+	file, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return runtime.GOARCH
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(scanner.Text(), ":"); ok && strings.TrimSpace(key) == "model name" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return runtime.GOARCH
+})
+
+// gitCommit prefers the revision stamped into the binary by go build and
+// falls back to asking git, which covers go run and go test.
+var gitCommit = sync.OnceValue(func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		var revision, modified string
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.modified":
+				modified = setting.Value
+			}
+		}
+
+		if revision != "" {
+			if modified == "true" {
+				revision += "-dirty"
+			}
+			return revision
+		}
+	}
+
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "unknown"
+	}
+
+	return strings.TrimSpace(string(out))
+})
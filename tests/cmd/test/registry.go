@@ -0,0 +1,97 @@
+package test
+
+import (
+	"fmt"
+	"iter"
+	"regexp"
+	"strings"
+)
+
+// Registry keeps named entries in declaration order, so experiments always
+// run, and results are always written, in the same order.
+type Registry[T any] []Entry[T]
+
+type Entry[T any] struct {
+	Name  string
+	Value T
+}
+
+func (r Registry[T]) All() iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		for _, e := range r {
+			if !yield(e.Name, e.Value) {
+				return
+			}
+		}
+	}
+}
+
+func (r Registry[T]) Lookup(name string) (T, bool) {
+	for _, e := range r {
+		if e.Name == name {
+			return e.Value, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+func (r Registry[T]) Get(name string) T {
+	v, _ := r.Lookup(name)
+	return v
+}
+
+func (r Registry[T]) Names() []string {
+	names := make([]string, len(r))
+	for i, e := range r {
+		names[i] = e.Name
+	}
+
+	return names
+}
+
+// Select keeps the named entries in the order they are given. An empty list
+// keeps all of them.
+func (r Registry[T]) Select(kind string, names []string) (Registry[T], error) {
+	if len(names) == 0 {
+		return r, nil
+	}
+
+	selected := make(Registry[T], 0, len(names))
+	for _, name := range names {
+		v, ok := r.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown %s %q", kind, name)
+		}
+		selected = append(selected, Entry[T]{name, v})
+	}
+
+	return selected, nil
+}
+
+// FILTER
+
+// Only restricts the cells that are run. It is matched against the cell name:
+// "<Experiment>/<Method>/<KeyKind>" for the CSV experiments and
+// "<Operation>/<Method>-<KeyKind>-<LoadFactor>-<Size>" for benchmarks.
+var Only *regexp.Regexp
+
+func SetOnly(pattern string) error {
+	if pattern == "" {
+		Only = nil
+		return nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("only: %w", err)
+	}
+	Only = re
+
+	return nil
+}
+
+func selected(parts ...string) bool {
+	return Only == nil || Only.MatchString(strings.Join(parts, "/"))
+}
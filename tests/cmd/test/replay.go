@@ -0,0 +1,110 @@
+package test
+
+import (
+	"analyze/internal/stats"
+	"analyze/internal/trace"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var replayHeader = append([]string{"operations", "ns_per_op", "probes_per_op", "collisions"}, summaryHeader...)
+
+// RunReplay replays every trace against every method and load factor. A
+// trace is named after its file, which takes the place of the key kind.
+func RunReplay(paths []string) error {
+	for _, path := range paths {
+		events, err := readTrace(path)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return fmt.Errorf("%s: empty trace", path)
+		}
+
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+		for method := range Factories.All() {
+			for _, loadFactor := range LoadFactors {
+				ReplayTest(name, events, method, loadFactor)
+			}
+		}
+	}
+
+	return nil
+}
+
+func readTrace(path string) ([]trace.Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r, err := trace.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	events, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return events, nil
+}
+
+// ReplayTest times the replay of a trace into a table that starts small and
+// grows as the trace makes it, and records its probes and collisions.
+func ReplayTest(name string, events []trace.Event, method string, loadFactor float64) {
+	if !selected("Replay", method, name) {
+		return
+	}
+
+	record := cellRecord("Replay", method, name)
+	record.LoadFactor, record.Size = loadFactor, len(events)
+	record.Metric, record.Unit = "time", "ns/op"
+
+	var probes, collisions []float64
+
+	summary := repeat([]string{"Replay", method, name, format(loadFactor)}, record, func(rep int, _ *rand.Rand, tableSeed uint64) float64 {
+		ht := Factories.Get(method)(8, tableSeed)
+		ht.SetLoadFactor(loadFactor)
+
+		// A trace inserts at most one key per event.
+		limitGrowth(ht, slotBudget(len(events)))
+
+		start := time.Now()
+		trace.Replay(ht, events)
+		elapsed := time.Since(start)
+
+		if stopped(ht) {
+			return math.NaN()
+		}
+
+		probes = append(probes, float64(ht.Probes())/float64(len(events)))
+		collisions = append(collisions, float64(ht.Collisions()))
+
+		perRep := record
+		perRep.Repetition = rep
+		perRep.Metric, perRep.Value, perRep.Unit = "probes", probes[len(probes)-1], "probes/op"
+		emit(perRep)
+		perRep.Metric, perRep.Value, perRep.Unit = "collisions", collisions[len(collisions)-1], "count"
+		emit(perRep)
+
+		return float64(elapsed.Nanoseconds()) / float64(len(events))
+	})
+	if summary.N == 0 {
+		return
+	}
+
+	metrics := [][]string{append(
+		getRecord(len(events), summary.Mean, stats.Summarize(probes).Mean, stats.Summarize(collisions).Mean), summaryRecord(summary)...,
+	)}
+
+	saveMetrics(filepath.Join(OutputDir, "Replay", method, format(loadFactor)), name, replayHeader, metrics)
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"analyze/internal/hash_table/resize"
+	"path/filepath"
+	"slices"
+	"strconv"
+)
+
+var resizeHeader = []string{"insert", "size", "reason", "old_capacity", "new_capacity", "moved", "duration_ns", "failed"}
+
+func RunResizeTest() {
+	for method := range Factories.All() {
+		for keyKind := range KeyGens.All() {
+			for _, loadFactor := range LoadFactors {
+				ResizeTest(method, keyKind, loadFactor)
+			}
+		}
+	}
+}
+
+// ResizeTest grows a table from its smallest capacity to the largest size
+// and writes every resize and rehash it reports, in the order they happen,
+// with the number of inserts made until then. Nested resizes, such as those
+// of a hopscotch reinsert, come before the resize they happen in.
+func ResizeTest(method string, keyKind string, loadFactor float64) {
+	if !selected("Resize", method, keyKind) || len(Sizes) == 0 {
+		return
+	}
+
+	size := slices.Max(Sizes)
+	cell := []string{"Resize", method, keyKind, format(loadFactor), format(size)}
+	rng, tableSeed := newCell(cell...)
+
+	ht := Factories.Get(method)(8, tableSeed)
+	ht.SetLoadFactor(loadFactor)
+
+	observable, ok := ht.(resize.Observable)
+	if !ok {
+		return
+	}
+
+	record := cellRecord("Resize", method, keyKind)
+	record.LoadFactor, record.Size = loadFactor, size
+
+	var (
+		metrics  [][]string
+		inserted int
+		counts   = map[resize.Reason]int{}
+	)
+
+	observe := func(e resize.Event) {
+		counts[e.Reason]++
+
+		record.Labels = map[string]string{
+			"insert":       strconv.Itoa(inserted),
+			"reason":       string(e.Reason),
+			"old_capacity": strconv.Itoa(e.OldCapacity),
+			"new_capacity": strconv.Itoa(e.NewCapacity),
+			"failed":       strconv.FormatBool(e.Failed),
+		}
+		record.Metric, record.Value, record.Unit = "resize", nanoseconds(e.Duration), "ns"
+		emit(record)
+
+		metrics = append(metrics, getRecord(inserted, ht.Size(), string(e.Reason), e.OldCapacity, e.NewCapacity,
+			e.Moved, int(e.Duration.Nanoseconds()), strconv.FormatBool(e.Failed)))
+	}
+
+	observable.OnResize(observe)
+
+	for key := range keyGenFor(method, keyKind, cellCapacity(size, loadFactor))(rng, size) {
+		limitGrowth(ht, slotBudget(inserted+1))
+		ht.Insert(key, key)
+		inserted++
+
+		if stopped(ht) || inserted%budgetCheck == 0 && overBudget(ht.Probes(), inserted) {
+			logAborted(cell)
+			record.Metric, record.Labels = "", nil
+			emit(abortedRecord(record, 0))
+			break
+		}
+	}
+
+	observable.OnResize(nil)
+
+	// The count of every reason, zeros included, so cells are comparable.
+	record.Unit = "count"
+	for _, reason := range resize.Reasons {
+		record.Metric, record.Value = "resizes", float64(counts[reason])
+		record.Labels = map[string]string{"reason": string(reason)}
+		emit(record)
+	}
+
+	saveMetrics(filepath.Join(OutputDir, "Resize", method, format(loadFactor)), keyKind, resizeHeader, metrics)
+}
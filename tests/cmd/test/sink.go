@@ -0,0 +1,119 @@
+package test
+
+import (
+	"analyze/internal/analysis"
+	"analyze/internal/results"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// OutputFormats are the formats results can be written in. csv is the nested
+// tree of CSV files; jsonl is a single results.jsonl in OutputDir with one
+// record per measured value and repetition.
+var OutputFormats = []string{"csv", "jsonl"}
+
+var Formats = []string{"csv"}
+
+// Sink receives every measured value while the experiments run. It is nil
+// unless a record format was requested.
+var Sink results.Sink
+
+func SetFormats(formats []string) error {
+	for _, format := range formats {
+		if !slices.Contains(OutputFormats, format) {
+			return fmt.Errorf("unsupported format %q, want one of %s", format, strings.Join(OutputFormats, ", "))
+		}
+	}
+
+	if len(formats) > 0 {
+		Formats = formats
+	}
+
+	return nil
+}
+
+// OpenSinks creates the sinks of the selected formats. The returned function
+// flushes and closes them once the experiments are done and writes their
+// metadata sidecars, like saveMetrics does for every CSV.
+func OpenSinks() (func() error, error) {
+	var (
+		sinks results.Multi
+		paths []string
+	)
+
+	if slices.Contains(Formats, "jsonl") {
+		if err := os.MkdirAll(OutputDir, 0o755); err != nil {
+			return nil, err
+		}
+
+		path := filepath.Join(OutputDir, "results.jsonl")
+		sink, err := results.NewJSONL(path)
+		if err != nil {
+			return nil, err
+		}
+		sinks, paths = append(sinks, sink), append(paths, path)
+	}
+
+	if len(sinks) == 0 {
+		return func() error { return nil }, nil
+	}
+
+	Sink = sinks
+
+	return func() error {
+		Sink = nil
+		if err := sinks.Close(); err != nil {
+			return err
+		}
+
+		for _, path := range paths {
+			saveMetadata(path)
+		}
+
+		return nil
+	}, nil
+}
+
+func writeCSV() bool {
+	return slices.Contains(Formats, "csv")
+}
+
+// cellRecord fills the dimensions shared by every record of a cell.
+func cellRecord(experiment string, variant string, keyKind string) results.Record {
+	return results.Record{Experiment: experiment, Method: baseMethod(variant), Hasher: variantHasher(variant), KeyKind: keyKind}
+}
+
+func emit(record results.Record) {
+	if Sink == nil {
+		return
+	}
+
+	if err := Sink.Write(record); err != nil {
+		log.Fatalf("failed to write %s record: %v", record.Metric, err)
+	}
+}
+
+// abortedRecord stands in for the values of an aborted repetition, which has
+// none, and names the metric it would have had.
+func abortedRecord(record results.Record, rep int) results.Record {
+	record.Labels = nil
+	if record.Metric != "" {
+		record.Labels = map[string]string{"metric": record.Metric}
+	}
+	record.Metric, record.Value, record.Unit, record.Repetition = "aborted", 1, "", rep
+
+	return record
+}
+
+// emitHistogram writes one record per bin, the bin itself is kept as a label.
+func emitHistogram(record results.Record, label string, histogram analysis.Histogram) {
+	for _, bin := range histogram.Keys() {
+		record.Labels = map[string]string{label: format(bin)}
+		record.Value = float64(histogram[bin])
+		emit(record)
+	}
+}
@@ -1,134 +1,321 @@
 package test
 
 import (
-	"encoding/csv"
-	"log"
+	"analyze/internal/hash_table"
+	"analyze/internal/results"
+	"analyze/internal/stats"
+	"analyze/internal/theory"
+	"math"
 	"math/bits"
-	"os"
+	"math/rand"
 	"path/filepath"
+	"slices"
 	"strconv"
 )
 
-const OutputDir = "results"
+var OutputDir = "results"
+
+var (
+	collisionsHeader      = append([]string{"size", "collisions", "mean"}, summaryHeader...)
+	collisionsSweepHeader = append([]string{"load_factor", "collisions", "collisions_per_key"}, summaryHeader...)
+	probesHeader          = append([]string{"load_factor", "probes", "predicted", "relative_error"}, summaryHeader...)
+)
 
 func RunCollisionsAndProbesTest() {
-	for method := range Factories {
-		for keyKind := range KeyGens {
+	RunCollisionsTest()
+	RunProbesTest()
+}
+
+func RunCollisionsTest() {
+	for method := range Factories.All() {
+		for keyKind := range KeyGens.All() {
 			for _, loadFactor := range LoadFactors {
 				CollisionsCountTest(method, keyKind, loadFactor)
 			}
 
+			CollisionsSweepTest(method, keyKind)
+		}
+	}
+}
+
+func RunProbesTest() {
+	for method := range Factories.All() {
+		for keyKind := range KeyGens.All() {
 			ProbesCountTest(method, keyKind)
+			ProbesMissTest(method, keyKind)
 		}
 	}
 }
 
 func CollisionsCountTest(method string, keyKind string, loadFactor float64) {
+	if !selected("Collision", method, keyKind) {
+		return
+	}
+
 	var (
 		collisionsMetrics [][]string
 	)
 
+	lfString := format(loadFactor)
+
+	record := cellRecord("Collision", method, keyKind)
+	record.LoadFactor, record.Metric, record.Unit = loadFactor, "collisions", "count"
+
 	for _, size := range Sizes {
-		ht := Factories[method](size)
-		ht.SetLoadFactor(loadFactor)
-		keysGen := KeyGens[keyKind](size)
+		record.Size = size
+
+		summary := repeat([]string{"Collision", method, keyKind, lfString, format(size)}, record, func(_ int, rng *rand.Rand, tableSeed uint64) float64 {
+			ht := Factories.Get(method)(size, tableSeed)
+			ht.SetLoadFactor(loadFactor)
+			keysGen := keyGenFor(method, keyKind, cellCapacity(size, loadFactor))(rng, size)
+
+			ht.ResetCollisions()
 
-		ht.ResetCollisions()
+			if _, ok := insertAll(ht, keysGen); !ok {
+				return math.NaN()
+			}
 
-		for key := range keysGen {
-			ht.Insert(key, key)
+			return float64(ht.Collisions())
+		})
+		if summary.N == 0 {
+			continue
 		}
 
-		collisionsMetrics = append(collisionsMetrics, getRecord(size, ht.Collisions()))
+		// collisions stays an integer count, as it was before repetitions;
+		// with more than one repetition it is the rounded mean.
+		collisionsMetrics = append(collisionsMetrics, append(
+			getRecord(size, int(math.Round(summary.Mean)), summary.Mean), summaryRecord(summary)...,
+		))
 	}
 
-	lfString := format(loadFactor)
-	saveMetrics(filepath.Join(OutputDir, "Collision", method, lfString), keyKind, collisionsMetrics)
+	saveMetrics(filepath.Join(OutputDir, "Collision", method, lfString), keyKind, collisionsHeader, collisionsMetrics)
+}
+
+// CollisionsSweepTest fills a table of SweepSize slots up to every load factor
+// of the sweep and records the collisions of the fill, which shows where each
+// method leaves its flat region.
+func CollisionsSweepTest(method string, keyKind string) {
+	if !selected("CollisionSweep", method, keyKind) {
+		return
+	}
+
+	var (
+		collisionsMetrics [][]string
+	)
+
+	record := cellRecord("CollisionSweep", method, keyKind)
+	record.Size, record.Metric, record.Unit = SweepSize, "collisions", "count"
+
+	for _, loadFactor := range SweepLoadFactors {
+		var insertions int
+
+		record.LoadFactor = loadFactor
+		summary := repeat([]string{"CollisionSweep", method, keyKind, format(loadFactor)}, record, func(_ int, rng *rand.Rand, tableSeed uint64) float64 {
+			ht, insertedKeys, ok := fillTable(rng, tableSeed, method, keyKind, loadFactor, SweepSize)
+			if !ok {
+				return math.NaN()
+			}
+			insertions = len(insertedKeys)
+
+			return float64(ht.Collisions())
+		})
+		if summary.N == 0 {
+			continue
+		}
+
+		collisionsMetrics = append(collisionsMetrics, append(
+			getRecord(loadFactor, summary.Mean, summary.Mean/float64(insertions)), summaryRecord(summary)...,
+		))
+	}
+
+	saveMetrics(filepath.Join(OutputDir, "CollisionSweep", method), keyKind, collisionsSweepHeader, collisionsMetrics)
 }
 
 func ProbesCountTest(method string, keyKind string) {
+	if !selected("Probes", method, keyKind) {
+		return
+	}
+
 	var (
-		size          = 5000
-		samples       = 1_000
+		samples       = SweepSamples
 		probesMetrics [][]string
 	)
 
-	loadFactors := []float64{0.5, 0.65, 0.75, 0.9}
+	record := cellRecord("Probes", method, keyKind)
+	record.Size, record.Metric, record.Unit = SweepSize, "probes", "probes/lookup"
 
-	for _, loadFactor := range loadFactors {
-		ht := Factories[method](size)
-		ht.SetLoadFactor(1.0)
+	for _, loadFactor := range SweepLoadFactors {
+		var predictions []prediction
 
-		desiredInsertions := int(loadFactor * float64(nextPowerOfTwo(size)))
-		keysGen := KeyGens[keyKind](desiredInsertions)
+		record.LoadFactor = loadFactor
+		summary := repeat([]string{"Probes", method, keyKind, format(loadFactor)}, record, func(rep int, rng *rand.Rand, tableSeed uint64) float64 {
+			ht, insertedKeys, ok := fillTable(rng, tableSeed, method, keyKind, loadFactor, SweepSize)
+			if !ok {
+				return math.NaN()
+			}
 
-		insertedKeys := make([]int, 0, desiredInsertions)
-		for key := range keysGen {
-			ht.Insert(key, key)
-			insertedKeys = append(insertedKeys, key)
-		}
+			ht.ResetProbes()
 
-		ht.ResetProbes()
+			rng.Shuffle(len(insertedKeys), func(i, j int) {
+				insertedKeys[i], insertedKeys[j] = insertedKeys[j], insertedKeys[i]
+			})
 
-		Random.Shuffle(len(insertedKeys), func(i, j int) {
-			insertedKeys[i], insertedKeys[j] = insertedKeys[j], insertedKeys[i]
-		})
+			for i := range samples {
+				key := insertedKeys[i%len(insertedKeys)]
+				ht.Get(key)
+			}
+
+			predictions = append(predictions, prediction{rep, modelFor(method).Successful(float64(ht.Size()) / float64(ht.Capacity()))})
 
-		for i := range samples {
-			key := insertedKeys[i%len(insertedKeys)]
-			ht.Get(key)
+			return float64(ht.Probes()) / float64(samples)
+		})
+		if summary.N == 0 {
+			continue
 		}
+		predicted := emitPredictions(record, predictions)
 
-		probesMetrics = append(probesMetrics, getRecord(loadFactor, float64(ht.Probes())/float64(samples)))
+		probesMetrics = append(probesMetrics, append(
+			getRecord(loadFactor, summary.Mean, predicted, theory.RelativeError(summary.Mean, predicted)), summaryRecord(summary)...,
+		))
 	}
 
-	saveMetrics(filepath.Join(OutputDir, "Probes", method), keyKind, probesMetrics)
+	saveMetrics(filepath.Join(OutputDir, "Probes", method), keyKind, probesHeader, probesMetrics)
 }
 
-func saveMetrics(dir, keyKind string, metrics [][]string) {
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		log.Fatalf("failed to create directory %s: %v", dir, err)
+func ProbesMissTest(method string, keyKind string) {
+	if !selected("ProbesMiss", method, keyKind) {
+		return
 	}
 
-	filePath := filepath.Join(dir, keyKind+".csv")
-	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE, os.ModePerm)
-	if err != nil {
-		log.Fatalf("failed to open file %s: %v", filePath, err)
-	}
-	defer file.Close()
+	var (
+		samples       = SweepSamples
+		probesMetrics [][]string
+	)
+
+	record := cellRecord("ProbesMiss", method, keyKind)
+	record.Size, record.Metric, record.Unit = SweepSize, "probes", "probes/lookup"
+
+	for _, loadFactor := range SweepLoadFactors {
+		var predictions []prediction
+
+		record.LoadFactor = loadFactor
+		summary := repeat([]string{"ProbesMiss", method, keyKind, format(loadFactor)}, record, func(rep int, rng *rand.Rand, tableSeed uint64) float64 {
+			ht, insertedKeys, ok := fillTable(rng, tableSeed, method, keyKind, loadFactor, SweepSize)
+			if !ok {
+				return math.NaN()
+			}
+
+			present := make(map[int]struct{}, len(insertedKeys))
+			for _, key := range insertedKeys {
+				present[key] = struct{}{}
+			}
+
+			missingKeys := make([]int, 0, samples)
+			for len(missingKeys) < samples {
+				key := rng.Int()
+				if _, ok := present[key]; !ok {
+					missingKeys = append(missingKeys, key)
+				}
+			}
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+			ht.ResetProbes()
 
-	for _, metric := range metrics {
-		if err = writer.Write(metric); err != nil {
-			log.Fatalf("failed to write CSV: %v", err)
+			for _, key := range missingKeys {
+				ht.Get(key)
+			}
+
+			predictions = append(predictions, prediction{rep, modelFor(method).Unsuccessful(float64(ht.Size()) / float64(ht.Capacity()))})
+
+			return float64(ht.Probes()) / float64(samples)
+		})
+		if summary.N == 0 {
+			continue
 		}
+		predicted := emitPredictions(record, predictions)
+
+		probesMetrics = append(probesMetrics, append(
+			getRecord(loadFactor, summary.Mean, predicted, theory.RelativeError(summary.Mean, predicted)), summaryRecord(summary)...,
+		))
 	}
+
+	saveMetrics(filepath.Join(OutputDir, "ProbesMiss", method), keyKind, probesHeader, probesMetrics)
 }
 
-func getRecord(metrics ...any) []string {
-	var record []string
+// repeat runs a measurement of one cell Repetitions times. Every repetition
+// derives its own seed from the cell name; the first one uses the plain name,
+// so a single repetition measures exactly what it did before. measure is
+// given the number of the repetition it runs.
+// Every value is also emitted as a copy of record. A measurement that gave up
+// on the budget returns NaN and is emitted as an "aborted" record.
+// The summary only covers the repetitions that finished. Its N is 0 when all
+// of them aborted, and the cell is then left out of the CSV.
+func repeat(cell []string, record results.Record, measure func(rep int, rng *rand.Rand, tableSeed uint64) float64) stats.Summary {
+	var values []float64
+
+	for rep := range max(Repetitions, 1) {
+		parts := cell
+		if rep > 0 {
+			parts = append(slices.Clone(cell), "rep"+strconv.Itoa(rep))
+		}
+
+		rng, tableSeed := newCell(parts...)
+		value := measure(rep, rng, tableSeed)
+
+		if math.IsNaN(value) {
+			logAborted(parts)
+			emit(abortedRecord(record, rep))
+			continue
+		}
+
+		values = append(values, value)
 
-	for _, metric := range metrics {
-		record = append(record, format(metric))
+		record.Value, record.Repetition = value, rep
+		emit(record)
 	}
 
-	return record
+	return stats.Summarize(values)
 }
 
-func format(value any) string {
-	switch v := value.(type) {
-	case string:
-		return v
-	case int:
-		return strconv.Itoa(v)
-	case float64:
-		return strconv.FormatFloat(v, 'f', 2, 64)
-	default:
-		log.Fatalf("unsupported metric type: %T", v)
-		return ""
+// prediction is the model prediction of the repetition rep.
+type prediction struct {
+	rep   int
+	value float64
+}
+
+// emitPredictions emits the model prediction of every repetition next to the
+// measured probes and returns their mean.
+func emitPredictions(record results.Record, predictions []prediction) float64 {
+	record.Metric = "predicted_probes"
+
+	values := make([]float64, len(predictions))
+	for i, p := range predictions {
+		record.Value, record.Repetition = p.value, p.rep
+		emit(record)
+
+		values[i] = p.value
 	}
+
+	return stats.Summarize(values).Mean
+}
+
+// summaryRecord holds the spread columns that follow the mean in the CSVs.
+func summaryRecord(s stats.Summary) []string {
+	return getRecord(s.StdDev, s.Min, s.Max, s.CILow, s.CIHigh)
+}
+
+// fillTable inserts loadFactor*capacity keys into a table that is not allowed
+// to grow on its own, so the table ends up at the requested load. It reports
+// false when the table went over the probe budget on the way.
+func fillTable(rng *rand.Rand, tableSeed uint64, method string, keyKind string, loadFactor float64, size int) (hash_table.HashTable, []int, bool) {
+	ht := Factories.Get(method)(size, tableSeed)
+	ht.SetLoadFactor(1.0)
+
+	desiredInsertions := int(loadFactor * float64(nextPowerOfTwo(size)))
+	keysGen := keyGenFor(method, keyKind, nextPowerOfTwo(size))(rng, desiredInsertions)
+
+	insertedKeys, ok := insertAll(ht, keysGen)
+
+	return ht, insertedKeys, ok
 }
 
 func nextPowerOfTwo(n int) int {
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestRepeatSummarizesFinishedRepetitions(t *testing.T) {
+	old := Repetitions
+	t.Cleanup(func() { Repetitions = old })
+	Repetitions = 3
+
+	tests := []struct {
+		name   string
+		values []float64
+		n      int
+		mean   float64
+	}{
+		{"none aborted", []float64{1, 2, 3}, 3, 2},
+		{"one aborted", []float64{1, math.NaN(), 3}, 2, 2},
+		{"all aborted", []float64{math.NaN(), math.NaN(), math.NaN()}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := repeat([]string{"Test", tt.name}, cellRecord("Test", "Method", "Key"), func(rep int, _ *rand.Rand, _ uint64) float64 {
+				return tt.values[rep]
+			})
+
+			if summary.N != tt.n || summary.Mean != tt.mean || math.IsNaN(summary.Max) {
+				t.Errorf("N %d, mean %v, max %v; want N %d, mean %v", summary.N, summary.Mean, summary.Max, tt.n, tt.mean)
+			}
+		})
+	}
+}
+
+// A prediction is recorded under the repetition it was made in, also when an
+// earlier repetition aborted.
+func TestPredictionsKeepRepetition(t *testing.T) {
+	oldReps, oldSink := Repetitions, Sink
+	t.Cleanup(func() { Repetitions, Sink = oldReps, oldSink })
+
+	var sink recordSink
+	Repetitions, Sink = 3, &sink
+
+	var predictions []prediction
+	record := cellRecord("Test", "Method", "Key")
+	repeat([]string{"Test", "predictions"}, record, func(rep int, _ *rand.Rand, _ uint64) float64 {
+		if rep == 0 {
+			return math.NaN()
+		}
+
+		predictions = append(predictions, prediction{rep, float64(rep)})
+		return 1
+	})
+	emitPredictions(record, predictions)
+
+	var reps []int
+	for _, r := range sink {
+		if r.Metric == "predicted_probes" {
+			if r.Value != float64(r.Repetition) {
+				t.Errorf("prediction %v under repetition %d", r.Value, r.Repetition)
+			}
+			reps = append(reps, r.Repetition)
+		}
+	}
+	if want := []int{1, 2}; !slices.Equal(reps, want) {
+		t.Errorf("predictions of repetitions %v, want %v", reps, want)
+	}
+}
@@ -5,13 +5,25 @@ import (
 	"analyze/internal/hash_table/chain"
 	"analyze/internal/hash_table/cuckoo"
 	double "analyze/internal/hash_table/double_hash"
+	"analyze/internal/hash_table/hasher"
 	"analyze/internal/hash_table/hopscotch"
 	robinhood "analyze/internal/hash_table/robin_hood"
+	"analyze/internal/theory"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"iter"
+	"math"
+	"math/bits"
 	"math/rand"
+	"strconv"
+	"strings"
 )
 
 // CONSTANT
+const DefaultSeed = 25
+
 type reserveStrategy int8
 
 const (
@@ -26,33 +38,163 @@ const (
 	lookupMiss
 )
 
+type Factory func(cap int, seed uint64) hash_table.HashTable
+
+type KeyGen func(r *rand.Rand, count int) iter.Seq[int]
+
 var (
-	Random = rand.New(rand.NewSource(25))
+	Seed int64 = DefaultSeed
 
 	Sizes = []int{1, 10, 100, 1000, 10_000, 100_000, 1_000_000, 10_000_000}
 
 	LoadFactors = []float64{0.4, 0.6, 0.8}
 
-	Factories = map[string]func(cap int) hash_table.HashTable{
-		"Chain":     func(c int) hash_table.HashTable { return chain.New(c) },
-		"Cuckoo":    func(c int) hash_table.HashTable { return cuckoo.New(c) },
-		"Double":    func(c int) hash_table.HashTable { return double.New(c) },
-		"Hopscotch": func(c int) hash_table.HashTable { return hopscotch.New(c) },
-		"RobinHood": func(c int) hash_table.HashTable { return robinhood.New(c) },
+	SweepLoadFactors = []float64{0.5, 0.65, 0.75, 0.9}
+
+	SweepSize = 5000
+
+	SweepSamples = 1_000
+
+	Repetitions = 1
+
+	// Factories build every method with its default hasher. Only cuckoo
+	// takes the seed, for its salts; the Multiplicative tables stay unseeded,
+	// see seedFor.
+	Factories = Registry[Factory]{
+		{"Chain", func(c int, _ uint64) hash_table.HashTable { return chain.New(c) }},
+		{"Cuckoo", func(c int, s uint64) hash_table.HashTable { return cuckoo.New(c, cuckoo.WithSeed(s)) }},
+		{"Double", func(c int, _ uint64) hash_table.HashTable { return double.New(c) }},
+		{"Hopscotch", func(c int, _ uint64) hash_table.HashTable { return hopscotch.New(c) }},
+		{"RobinHood", func(c int, _ uint64) hash_table.HashTable { return robinhood.New(c) }},
+	}
+
+	Models = map[string]theory.Model{
+		"Chain":     theory.Chaining,
+		"Cuckoo":    theory.Cuckoo,
+		"Double":    theory.UniformHashing,
+		"Hopscotch": theory.Chaining,
+		"RobinHood": theory.RobinHood,
 	}
 
-	KeyGens = map[string]func(int) iter.Seq[int]{
-		"RandomKey":     func(count int) iter.Seq[int] { return genRandomKeys(count) },
-		"SequentialKey": func(count int) iter.Seq[int] { return genSequentialKeys(count) },
+	KeyGens = Registry[KeyGen]{
+		{"RandomKey", func(r *rand.Rand, count int) iter.Seq[int] { return genRandomKeys(r, count) }},
+		{"SequentialKey", func(_ *rand.Rand, count int) iter.Seq[int] { return genSequentialKeys(count) }},
+		{"StridedKey", genStridedKeys},
+		{"ClusteredKey", genClusteredKeys},
+		{"HighBitsKey", func(_ *rand.Rand, count int) iter.Seq[int] { return genHighBitsKeys(count) }},
+		{"PointerKey", genPointerKeys},
+		{"TimestampKey", genTimestampKeys},
+		{AdversarialKey, AdversarialKeys(hasher.Multiplicative, attackCapacity)},
 	}
 )
 
+// SELECTION
+
+// SelectMethods keeps only the named factories. An empty list keeps all of them.
+func SelectMethods(names []string) error {
+	selected, err := Factories.Select("method", names)
+	if err != nil {
+		return err
+	}
+	Factories = selected
+
+	return nil
+}
+
+// SelectKeyGens keeps only the named key generators. An empty list keeps all of them.
+func SelectKeyGens(names []string) error {
+	selected, err := KeyGens.Select("key generator", names)
+	if err != nil {
+		return err
+	}
+	KeyGens = selected
+
+	return nil
+}
+
+func SetSeed(seed int64) {
+	Seed = seed
+}
+
+// SEEDING
+
+// CellSeed derives the seed of one experiment cell from the master Seed and
+// the cell name, so a cell gives the same result whatever runs before it.
+func CellSeed(cell string) int64 {
+	h := fnv.New64a()
+	_ = binary.Write(h, binary.LittleEndian, Seed)
+	_, _ = h.Write([]byte(cell))
+
+	return int64(h.Sum64())
+}
+
+// newCell returns the generator of the keys and shuffles of a cell and the
+// seed of its table.
+func newCell(parts ...string) (*rand.Rand, uint64) {
+	rng := rand.New(rand.NewSource(CellSeed(strings.Join(parts, "/"))))
+	return rng, rng.Uint64()
+}
+
+// SWEEP
+
+type Sweep struct {
+	From, To, Step float64
+}
+
+// ParseSweep reads a sweep written as "from:to:step", e.g. "0.05:0.99:0.01".
+func ParseSweep(s string) (Sweep, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return Sweep{}, fmt.Errorf("sweep %q: want from:to:step", s)
+	}
+
+	var values [3]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return Sweep{}, fmt.Errorf("sweep %q: %w", s, err)
+		}
+		values[i] = v
+	}
+
+	sweep := Sweep{From: values[0], To: values[1], Step: values[2]}
+	if err := sweep.validate(); err != nil {
+		return Sweep{}, fmt.Errorf("sweep %q: %w", s, err)
+	}
+
+	return sweep, nil
+}
+
+func (s Sweep) validate() error {
+	if s.Step <= 0 || s.From > s.To || s.From <= 0 || s.To >= 1 {
+		return errors.New("want 0 < from <= to < 1 and step > 0")
+	}
+
+	return nil
+}
+
+// Values computes every point from the start instead of accumulating Step,
+// so that 0.05:0.99:0.01 ends exactly at 0.99.
+func (s Sweep) Values() []float64 {
+	var values []float64
+
+	for i := 0; ; i++ {
+		v := math.Round((s.From+float64(i)*s.Step)*1e9) / 1e9
+		if v > s.To {
+			break
+		}
+		values = append(values, v)
+	}
+
+	return values
+}
+
 // KEY_GENERATORS
 
-func genRandomKeys(count int) iter.Seq[int] {
+func genRandomKeys(r *rand.Rand, count int) iter.Seq[int] {
 	return func(yield func(int) bool) {
 		for range count {
-			if !yield(Random.Int()) {
+			if !yield(r.Int()) {
 				return
 			}
 		}
@@ -68,3 +210,89 @@ func genSequentialKeys(count int) iter.Seq[int] {
 		}
 	}
 }
+
+// Structured keys. Each shape is one that multiplicative hashing with a
+// power-of-two mask may handle worse than random keys.
+const (
+	keyStride      = 1 << 12
+	keyClusterSize = 64
+	pointerBase    = 0xc000000000
+	pointerAlign   = 16
+	timestampBase  = 1_700_000_000_000_000_000
+)
+
+// genStridedKeys is an arithmetic progression with a power-of-two stride
+// from a random start, like page-aligned offsets.
+func genStridedKeys(r *rand.Rand, count int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		start := r.Intn(1 << 30)
+		for i := range count {
+			if !yield(start + i*keyStride) {
+				return
+			}
+		}
+	}
+}
+
+// genClusteredKeys gives runs of consecutive keys starting at random
+// cluster-aligned bases, like ids allocated in blocks. A base is never drawn
+// twice, so the keys are unique.
+func genClusteredKeys(r *rand.Rand, count int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		var (
+			base  int
+			bases = map[int]bool{}
+		)
+		for i := range count {
+			if i%keyClusterSize == 0 {
+				for base = r.Int() &^ (keyClusterSize - 1); bases[base]; {
+					base = r.Int() &^ (keyClusterSize - 1)
+				}
+				bases[base] = true
+			}
+			if !yield(base + i%keyClusterSize) {
+				return
+			}
+		}
+	}
+}
+
+// genHighBitsKeys counts in bit-reversed order, so the keys differ only in
+// their high bits and all low bits are zero.
+func genHighBitsKeys(count int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := range count {
+			if !yield(int(bits.Reverse64(uint64(i)) >> 1)) {
+				return
+			}
+		}
+	}
+}
+
+// genPointerKeys looks like the addresses of consecutive heap allocations:
+// aligned and increasing by small multiples of the alignment.
+func genPointerKeys(r *rand.Rand, count int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		ptr := pointerBase
+		for range count {
+			ptr += pointerAlign * (1 + r.Intn(4))
+			if !yield(ptr) {
+				return
+			}
+		}
+	}
+}
+
+// genTimestampKeys looks like nanosecond timestamps of events about a
+// microsecond apart, with jitter.
+func genTimestampKeys(r *rand.Rand, count int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		ts := timestampBase
+		for range count {
+			ts += 500 + r.Intn(1000)
+			if !yield(ts) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package test
+
+import (
+	"iter"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestParseSweep(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Sweep
+		wantErr bool
+	}{
+		{"0.05:0.99:0.01", Sweep{0.05, 0.99, 0.01}, false},
+		{"0.5:0.5:0.1", Sweep{0.5, 0.5, 0.1}, false},
+		{"0.05:0.99", Sweep{}, true},
+		{"0.05:0.99:x", Sweep{}, true},
+		{"0:0.99:0.01", Sweep{}, true},
+		{"0.05:1:0.01", Sweep{}, true},
+		{"0.9:0.5:0.01", Sweep{}, true},
+		{"0.05:0.99:0", Sweep{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSweep(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSweep(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSweep(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSweepValues(t *testing.T) {
+	values := Sweep{0.05, 0.99, 0.01}.Values()
+
+	if len(values) != 95 || values[0] != 0.05 || values[len(values)-1] != 0.99 {
+		t.Errorf("got %d values from %v to %v, want 95 from 0.05 to 0.99", len(values), values[0], values[len(values)-1])
+	}
+	if !slices.IsSorted(values) {
+		t.Errorf("values not increasing: %v", values)
+	}
+
+	if got := (Sweep{0.1, 0.35, 0.1}).Values(); !slices.Equal(got, []float64{0.1, 0.2, 0.3}) {
+		t.Errorf("0.1:0.35:0.1 = %v, want [0.1 0.2 0.3]", got)
+	}
+	if got := (Sweep{0.5, 0.5, 0.1}).Values(); !slices.Equal(got, []float64{0.5}) {
+		t.Errorf("0.5:0.5:0.1 = %v, want [0.5]", got)
+	}
+}
+
+func TestStructuredKeys(t *testing.T) {
+	const count = 5000
+
+	tests := []struct {
+		name string
+		gen  KeyGen
+		// shape checks key i given the key before it.
+		shape func(i, key, prev int) bool
+	}{
+		{"StridedKey", genStridedKeys, func(i, key, prev int) bool {
+			return i == 0 || key-prev == keyStride
+		}},
+		{"ClusteredKey", genClusteredKeys, func(i, key, prev int) bool {
+			if i%keyClusterSize == 0 {
+				return key%keyClusterSize == 0
+			}
+			return key == prev+1
+		}},
+		{"HighBitsKey", func(_ *rand.Rand, n int) iter.Seq[int] { return genHighBitsKeys(n) }, func(_, key, _ int) bool {
+			// count < 1<<13, so only the 13 bits below the sign bit are used.
+			return key&(1<<50-1) == 0
+		}},
+		{"PointerKey", genPointerKeys, func(i, key, prev int) bool {
+			return key%pointerAlign == 0 && (i == 0 || key > prev && key-prev <= 4*pointerAlign)
+		}},
+		{"TimestampKey", genTimestampKeys, func(i, key, prev int) bool {
+			return i == 0 || key-prev >= 500 && key-prev < 1500
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				seen = map[int]bool{}
+				prev int
+				i    int
+			)
+
+			for key := range tt.gen(rand.New(rand.NewSource(1)), count) {
+				if seen[key] {
+					t.Fatalf("key %d: %#x repeats", i, key)
+				}
+				seen[key] = true
+
+				if !tt.shape(i, key, prev) {
+					t.Fatalf("key %d: %#x does not follow %#x", i, key, prev)
+				}
+				prev = key
+				i++
+			}
+
+			if i != count {
+				t.Errorf("%d keys, want %d", i, count)
+			}
+		})
+	}
+}
+
+// repeatSource returns its values in turn, so a generator can be made to
+// draw the same number twice.
+type repeatSource []int64
+
+func (s *repeatSource) Int63() int64 {
+	v := (*s)[0]
+	*s = (*s)[1:]
+	return v
+}
+
+func (s *repeatSource) Seed(int64) {}
+
+func TestClusteredKeysRedrawBase(t *testing.T) {
+	src := repeatSource{1 << 20, 1 << 20, 2 << 20}
+
+	var keys []int
+	for key := range genClusteredKeys(rand.New(&src), 2*keyClusterSize) {
+		keys = append(keys, key)
+	}
+
+	if keys[0] != 1<<20 || keys[keyClusterSize] != 2<<20 {
+		t.Errorf("clusters start at %#x and %#x, want %#x and %#x", keys[0], keys[keyClusterSize], 1<<20, 2<<20)
+	}
+}
@@ -0,0 +1,143 @@
+package test
+
+import (
+	"analyze/internal/workload"
+	"log"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// Presets are the workloads the workload experiment runs, see
+	// workload.Presets.
+	Presets = presetRegistry()
+
+	// WorkloadOperations and WorkloadDuration bound every workload cell, the
+	// one reached first ends it. Zero leaves a bound out.
+	WorkloadOperations = 100_000
+	WorkloadDuration   time.Duration
+)
+
+var workloadHeader = append([]string{"size", "repetition", "op", "throughput"}, latencyHeader...)
+
+func presetRegistry() Registry[workload.Workload] {
+	var presets Registry[workload.Workload]
+	for _, w := range workload.Presets {
+		presets = append(presets, Entry[workload.Workload]{w.Name, w})
+	}
+
+	return presets
+}
+
+// SelectPresets keeps only the named workloads. An empty list keeps all of them.
+func SelectPresets(names []string) error {
+	selected, err := Presets.Select("workload", names)
+	if err != nil {
+		return err
+	}
+	Presets = selected
+
+	return nil
+}
+
+func RunWorkloads() {
+	for name, w := range Presets.All() {
+		for method := range Factories.All() {
+			for keyKind := range KeyGens.All() {
+				for _, loadFactor := range LoadFactors {
+					WorkloadTest(name, w, method, keyKind, loadFactor)
+				}
+			}
+		}
+	}
+}
+
+// WorkloadTest loads every size of records into a table and runs the
+// workload against it, recording the throughput and the latencies of every
+// kind of operation.
+func WorkloadTest(name string, w workload.Workload, method string, keyKind string, loadFactor float64) {
+	if !selected("Workload", name, method, keyKind) {
+		return
+	}
+
+	var metrics [][]string
+
+	record := cellRecord("Workload", method, keyKind)
+	record.LoadFactor = loadFactor
+
+	for _, size := range Sizes {
+		record.Size = size
+
+		for rep := range max(Repetitions, 1) {
+			parts := []string{"Workload", name, method, keyKind, format(loadFactor), format(size)}
+			if rep > 0 {
+				parts = append(parts, "rep"+strconv.Itoa(rep))
+			}
+
+			rng, tableSeed := newCell(parts...)
+			ht := Factories.Get(method)(size, tableSeed)
+			ht.SetLoadFactor(loadFactor)
+
+			// The probe budget only guards the load phase: scans make many
+			// lookups per inserted key on any table.
+			var pulled int
+			aborted := false
+			keys := func(yield func(int) bool) {
+				for key := range keyGenFor(method, keyKind, cellCapacity(size, loadFactor))(rng, math.MaxInt) {
+					pulled++
+					if stopped(ht) || pulled <= size && pulled%budgetCheck == 0 && overBudget(ht.Probes(), pulled) {
+						aborted = true
+						return
+					}
+					limitGrowth(ht, slotBudget(pulled))
+					if !yield(key) {
+						return
+					}
+				}
+			}
+
+			result, err := workload.Run(ht, w, workload.Options{
+				Records:    size,
+				Operations: WorkloadOperations,
+				Duration:   WorkloadDuration,
+				ZipfSkew:   ZipfSkew,
+				Rand:       rng,
+				Keys:       keys,
+			})
+			if err != nil {
+				log.Fatalf("%s: %v", strings.Join(parts, "/"), err)
+			}
+
+			record.Repetition = rep
+			record.Labels = map[string]string{"workload": name}
+			record.Metric, record.Unit = "throughput", "ops/s"
+
+			if aborted || stopped(ht) {
+				logAborted(parts)
+				abortedRec := abortedRecord(record, rep)
+				abortedRec.Labels["workload"] = name
+				emit(abortedRec)
+				continue
+			}
+
+			record.Value = result.Throughput()
+			emit(record)
+
+			for _, l := range result.Latencies {
+				summary := workloadLatency(l)
+				metrics = append(metrics, append(
+					getRecord(size, rep, l.Op.String(), float64(l.Count)/result.Elapsed.Seconds()), summary.columns()...,
+				))
+
+				opRecord := record
+				opRecord.Labels = map[string]string{"workload": name, "op": l.Op.String()}
+				emitLatency(opRecord, summary)
+			}
+		}
+	}
+
+	saveMetrics(filepath.Join(OutputDir, "Workload", name, method, format(loadFactor)), keyKind, workloadHeader, metrics)
+}
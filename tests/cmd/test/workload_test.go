@@ -0,0 +1,48 @@
+package test
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Every repetition writes its own rows, told apart by the repetition column.
+func TestWorkloadRepetitionColumn(t *testing.T) {
+	oldReps, oldSizes, oldOps := Repetitions, Sizes, WorkloadOperations
+	oldDir, oldSink, oldFormats := OutputDir, Sink, Formats
+	t.Cleanup(func() {
+		Repetitions, Sizes, WorkloadOperations = oldReps, oldSizes, oldOps
+		OutputDir, Sink, Formats = oldDir, oldSink, oldFormats
+	})
+
+	Repetitions, Sizes, WorkloadOperations = 2, []int{256}, 100
+	OutputDir, Sink, Formats = t.TempDir(), nil, []string{"csv"}
+
+	WorkloadTest("C", Presets.Get("C"), "RobinHood", "RandomKey", 0.5)
+
+	file, err := os.Open(filepath.Join(OutputDir, "Workload", "C", "RobinHood", "0.50", "RandomKey.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	column := slices.Index(rows[0], "repetition")
+	if column < 0 {
+		t.Fatalf("header %v has no repetition column", rows[0])
+	}
+
+	var reps []string
+	for _, row := range rows[1:] {
+		reps = append(reps, row[column])
+	}
+	if want := []string{"0", "1"}; !slices.Equal(reps, want) {
+		t.Errorf("repetitions %v, want %v", reps, want)
+	}
+}
@@ -0,0 +1,109 @@
+package analysis
+
+import (
+	"analyze/internal/hash_table/layout"
+	"slices"
+)
+
+// Histogram maps a length (or distance) to the number of times it was seen.
+type Histogram map[int]int
+
+func (h Histogram) Keys() []int {
+	keys := make([]int, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+type Report struct {
+	Slots    int
+	Occupied int
+	// Occupancy is the share of occupied slots of every array.
+	Occupancy     []float64
+	Clusters      Histogram
+	Displacements Histogram
+	EmptyRuns     Histogram
+	Tombstones    []int
+}
+
+// Analyze walks every array of the snapshot. A cluster is a maximal run of
+// non-empty slots (tombstones included, since probing walks through them),
+// an empty run is a maximal run of empty slots. Runs wrap around the end of
+// each array. Tombstone positions are global: the arrays are numbered one
+// after another. Scattered snapshots get no clusters or empty runs.
+func Analyze(snapshot layout.Snapshot) Report {
+	report := Report{
+		Clusters:      Histogram{},
+		Displacements: Histogram{},
+		EmptyRuns:     Histogram{},
+	}
+
+	offset := 0
+	for _, slots := range snapshot.Tables {
+		occupied := 0
+		for idx, slot := range slots {
+			switch slot.State {
+			case layout.Occupied:
+				occupied++
+				report.Displacements[slot.Dist]++
+			case layout.Tombstone:
+				report.Tombstones = append(report.Tombstones, offset+idx)
+			}
+		}
+
+		if !snapshot.Scattered {
+			countRuns(slots, report.Clusters, report.EmptyRuns)
+		}
+
+		report.Occupied += occupied
+		report.Occupancy = append(report.Occupancy, float64(occupied)/float64(max(len(slots), 1)))
+		report.Slots += len(slots)
+		offset += len(slots)
+	}
+
+	return report
+}
+
+func countRuns(slots []layout.Slot, clusters, emptyRuns Histogram) {
+	n := len(slots)
+	isEmpty := func(i int) bool { return slots[i%n].State == layout.Empty }
+
+	// Start right after a boundary so that no run is split by the wrap-around.
+	start := -1
+	for i := range n {
+		if isEmpty(i) != isEmpty(i+n-1) {
+			start = i
+			break
+		}
+	}
+
+	if start == -1 {
+		if n > 0 && isEmpty(0) {
+			emptyRuns[n]++
+		} else if n > 0 {
+			clusters[n]++
+		}
+
+		return
+	}
+
+	run := 0
+	for i := start; i < start+n; i++ {
+		run++
+
+		if isEmpty(i) == isEmpty(i+1) && i+1 < start+n {
+			continue
+		}
+
+		if isEmpty(i) {
+			emptyRuns[run]++
+		} else {
+			clusters[run]++
+		}
+
+		run = 0
+	}
+}
@@ -0,0 +1,128 @@
+package analysis
+
+import (
+	"analyze/internal/hash_table/layout"
+	"reflect"
+	"testing"
+)
+
+func slotsOf(pattern string) []layout.Slot {
+	slots := make([]layout.Slot, len(pattern))
+	for i, c := range pattern {
+		switch c {
+		case 'x':
+			slots[i] = layout.Slot{State: layout.Occupied, Dist: 1}
+		case 't':
+			slots[i] = layout.Slot{State: layout.Tombstone}
+		}
+	}
+
+	return slots
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name       string
+		tables     []string
+		clusters   Histogram
+		emptyRuns  Histogram
+		tombstones []int
+	}{
+		{"empty", []string{"...."}, Histogram{}, Histogram{4: 1}, nil},
+		{"full", []string{"xxxx"}, Histogram{4: 1}, Histogram{}, nil},
+		{"wrap", []string{"x..xx"}, Histogram{3: 1}, Histogram{2: 1}, nil},
+		{"tombstones", []string{"xt..x.", ".t"}, Histogram{2: 1, 1: 2}, Histogram{2: 1, 1: 2}, []int{1, 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var snapshot layout.Snapshot
+			for _, pattern := range tt.tables {
+				snapshot.Tables = append(snapshot.Tables, slotsOf(pattern))
+			}
+
+			report := Analyze(snapshot)
+
+			if !reflect.DeepEqual(report.Clusters, tt.clusters) {
+				t.Errorf("clusters: got %v, want %v", report.Clusters, tt.clusters)
+			}
+			if !reflect.DeepEqual(report.EmptyRuns, tt.emptyRuns) {
+				t.Errorf("empty runs: got %v, want %v", report.EmptyRuns, tt.emptyRuns)
+			}
+			if !reflect.DeepEqual(report.Tombstones, tt.tombstones) {
+				t.Errorf("tombstones: got %v, want %v", report.Tombstones, tt.tombstones)
+			}
+		})
+	}
+}
+
+func TestAnalyzeScattered(t *testing.T) {
+	// The keys of the first table sit at their first choice.
+	first := slotsOf("xxx.")
+	for i := range first {
+		first[i].Dist = 0
+	}
+	snapshot := layout.Snapshot{Tables: [][]layout.Slot{first, slotsOf(".x.x")}, Scattered: true}
+
+	report := Analyze(snapshot)
+
+	if report.Slots != 8 || report.Occupied != 5 {
+		t.Errorf("slots %d, occupied %d, want 8 and 5", report.Slots, report.Occupied)
+	}
+	if want := []float64{0.75, 0.5}; !reflect.DeepEqual(report.Occupancy, want) {
+		t.Errorf("occupancy %v, want %v", report.Occupancy, want)
+	}
+	if want := (Histogram{0: 3, 1: 2}); !reflect.DeepEqual(report.Displacements, want) {
+		t.Errorf("displacements %v, want %v", report.Displacements, want)
+	}
+	if len(report.Clusters) != 0 || len(report.EmptyRuns) != 0 {
+		t.Errorf("scattered snapshot got clusters %v, empty runs %v", report.Clusters, report.EmptyRuns)
+	}
+}
+
+func TestAnalyzeChains(t *testing.T) {
+	t.Run("uniform", func(t *testing.T) {
+		lengths := make([]int, 1000)
+		for i := range lengths {
+			lengths[i] = 1
+		}
+
+		report := AnalyzeChains(lengths)
+
+		if report.Empty != 0 || report.MaxLength != 1 || report.LoadFactor != 1 {
+			t.Errorf("unexpected summary: %+v", report)
+		}
+		// A perfectly even spread is far from Poisson(1).
+		if report.ChiSquared < 100 {
+			t.Errorf("chi-squared too small for an even spread: %v", report.ChiSquared)
+		}
+	})
+
+	t.Run("poisson", func(t *testing.T) {
+		// Counts close to Poisson(1) for 10000 buckets.
+		counts := []int{3679, 3679, 1839, 613, 153, 31, 6}
+		var lengths []int
+		for length, count := range counts {
+			for range count {
+				lengths = append(lengths, length)
+			}
+		}
+
+		report := AnalyzeChains(lengths)
+
+		if report.Buckets != 10000 || report.MaxLength != 6 {
+			t.Errorf("unexpected summary: %+v", report)
+		}
+		if report.ChiSquared > 1 {
+			t.Errorf("chi-squared too large for a Poisson sample: %v", report.ChiSquared)
+		}
+
+		total := 0
+		for _, bin := range report.Bins {
+			total += bin.Observed
+		}
+		if total != report.Buckets {
+			t.Errorf("bins cover %d buckets, want %d", total, report.Buckets)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package analysis
+
+import "math"
+
+// minExpected is the smallest expected bin count the chi-squared test is
+// trusted with; rarer chain lengths are pooled into the tail bin.
+const minExpected = 5.
+
+type ChainBin struct {
+	Length   int
+	Observed int
+	Expected float64
+}
+
+type ChainReport struct {
+	Buckets          int
+	Size             int
+	LoadFactor       float64
+	Lengths          Histogram
+	Empty            int
+	MaxLength        int
+	Bins             []ChainBin
+	ChiSquared       float64
+	DegreesOfFreedom int
+}
+
+// AnalyzeChains compares the bucket length histogram with the Poisson
+// distribution of mean n/m that an ideal hash function would give. The last
+// bin of Bins collects every length from its Length upwards.
+func AnalyzeChains(lengths []int) ChainReport {
+	report := ChainReport{
+		Buckets: len(lengths),
+		Lengths: Histogram{},
+	}
+
+	for _, length := range lengths {
+		report.Size += length
+		report.Lengths[length]++
+		report.MaxLength = max(report.MaxLength, length)
+	}
+
+	if report.Buckets == 0 {
+		return report
+	}
+
+	report.Empty = report.Lengths[0]
+	report.LoadFactor = float64(report.Size) / float64(report.Buckets)
+
+	m := float64(report.Buckets)
+	pmf := math.Exp(-report.LoadFactor)
+	cdf := 0.
+
+	for k := 0; ; k++ {
+		tail := m * (1 - cdf)
+		expected := m * pmf
+
+		if k == report.MaxLength || tail-expected < minExpected {
+			observed := 0
+			for length, count := range report.Lengths {
+				if length >= k {
+					observed += count
+				}
+			}
+
+			report.Bins = append(report.Bins, ChainBin{Length: k, Observed: observed, Expected: tail})
+			break
+		}
+
+		report.Bins = append(report.Bins, ChainBin{Length: k, Observed: report.Lengths[k], Expected: expected})
+
+		cdf += pmf
+		pmf *= report.LoadFactor / float64(k+1)
+	}
+
+	for _, bin := range report.Bins {
+		if bin.Expected > 0 {
+			diff := float64(bin.Observed) - bin.Expected
+			report.ChiSquared += diff * diff / bin.Expected
+		}
+	}
+
+	report.DegreesOfFreedom = max(len(report.Bins)-1, 0)
+
+	return report
+}
@@ -0,0 +1,270 @@
+package benchfmt
+
+import (
+	"analyze/internal/results"
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type Value struct {
+	Value float64
+	Unit  string
+}
+
+// Result is one benchmark line. Name is the full name without the Benchmark
+// prefix and the -GOMAXPROCS suffix; Operation is its top-level part and Sub
+// the sub-benchmark path below it.
+type Result struct {
+	Name       string
+	Operation  string
+	Sub        string
+	Procs      int
+	Iterations int
+	Values     []Value
+	// Config holds the "key: value" lines (goos, goarch, pkg, cpu, ...) in
+	// effect when the line was read.
+	Config map[string]string
+
+	procsSuffix bool
+}
+
+func (r Result) Value(unit string) (float64, bool) {
+	for _, v := range r.Values {
+		if v.Unit == unit {
+			return v.Value, true
+		}
+	}
+
+	return 0, false
+}
+
+// Parse reads every benchmark line of r and skips everything else: test
+// logs, PASS and ok lines. A line that starts like a result but is
+// malformed is an error.
+func Parse(r io.Reader) ([]Result, error) {
+	var (
+		parsed  []Result
+		config  = map[string]string{}
+		scanner = bufio.NewScanner(r)
+		lineNo  int
+	)
+
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+
+		if key, value, ok := configLine(line); ok {
+			config = cloneWith(config, key, value)
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+
+		iterations, err := strconv.Atoi(fields[1])
+		if err != nil {
+			// "BenchmarkX" alone or followed by log output: not a result.
+			continue
+		}
+
+		result, err := parseResult(fields[0], iterations, fields[2:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		result.Config = config
+
+		parsed = append(parsed, result)
+	}
+
+	return parsed, scanner.Err()
+}
+
+func parseResult(name string, iterations int, fields []string) (Result, error) {
+	if len(fields)%2 != 0 {
+		return Result{}, fmt.Errorf("%s: odd number of value and unit fields", name)
+	}
+
+	result := Result{Iterations: iterations, Procs: 1}
+
+	name = strings.TrimPrefix(name, "Benchmark")
+	if i := strings.LastIndexByte(name, '-'); i >= 0 {
+		if procs, err := strconv.Atoi(name[i+1:]); err == nil {
+			name, result.Procs, result.procsSuffix = name[:i], procs, true
+		}
+	}
+
+	result.Name = name
+	result.Operation, result.Sub, _ = strings.Cut(name, "/")
+
+	for i := 0; i < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return Result{}, fmt.Errorf("%s: value %q: %w", name, fields[i], err)
+		}
+		result.Values = append(result.Values, Value{value, fields[i+1]})
+	}
+
+	return result, nil
+}
+
+// configLine recognises the "key: value" lines go test prints before the
+// results. Keys start with a lower-case letter and have no spaces or
+// upper-case letters.
+func configLine(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok || key == "" || !unicode.IsLower(rune(key[0])) || strings.ContainsFunc(key, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsUpper(r)
+	}) {
+		return "", "", false
+	}
+
+	return key, strings.TrimSpace(value), true
+}
+
+func cloneWith(config map[string]string, key, value string) map[string]string {
+	clone := make(map[string]string, len(config)+1)
+	for k, v := range config {
+		clone[k] = v
+	}
+	clone[key] = value
+
+	return clone
+}
+
+// Cell decodes the sub-benchmark name. go test leaves the -GOMAXPROCS suffix
+// out when it is 1, so a trailing number that was taken for it is given back
+// to the name if the name cannot be decoded without it.
+func (r Result) Cell() Cell {
+	cell := ParseCell(r.Sub)
+	if !r.procsSuffix || cell.Labels == nil {
+		return cell
+	}
+
+	if withSuffix := ParseCell(r.Sub + "-" + strconv.Itoa(r.Procs)); withSuffix.Labels == nil {
+		return withSuffix
+	}
+
+	return cell
+}
+
+// Cell is what a sub-benchmark name says about the measured cell.
+type Cell struct {
+	Method     string
+	Hasher     string
+	KeyKind    string
+	LoadFactor float64
+	Size       int
+	// Labels keeps the parts of the name that are not one of the above.
+	Labels map[string]string
+}
+
+// ParseCell decodes a sub-benchmark name. It understands the positional
+// "<Method>-<KeyKind>-<LoadFactor>-<Size>" names of the harness, read from
+// the right so a method may contain dashes, as well as key=value parts
+// separated by "/" (method=Chain/keys=RandomKey/lf=0.8/size=1000). A name in
+// neither form ends up whole in Labels["name"].
+func ParseCell(sub string) Cell {
+	if strings.Contains(sub, "=") {
+		return keyValueCell(sub)
+	}
+
+	parts := strings.Split(sub, "-")
+	if len(parts) >= 4 {
+		size, sizeErr := strconv.Atoi(parts[len(parts)-1])
+		loadFactor, lfErr := strconv.ParseFloat(parts[len(parts)-2], 64)
+
+		if sizeErr == nil && lfErr == nil {
+			cell := Cell{KeyKind: parts[len(parts)-3], LoadFactor: loadFactor, Size: size}
+			cell.Method, cell.Hasher = splitVariant(strings.Join(parts[:len(parts)-3], "-"))
+
+			return cell
+		}
+	}
+
+	if sub == "" {
+		return Cell{}
+	}
+
+	return Cell{Labels: map[string]string{"name": sub}}
+}
+
+func keyValueCell(sub string) Cell {
+	var cell Cell
+
+	for _, part := range strings.Split(sub, "/") {
+		key, value, _ := strings.Cut(part, "=")
+
+		known := true
+		switch strings.ToLower(key) {
+		case "method":
+			cell.Method, cell.Hasher = splitVariant(value)
+		case "hasher":
+			cell.Hasher = value
+		case "key", "keys", "keykind":
+			cell.KeyKind = value
+		case "lf", "loadfactor":
+			loadFactor, err := strconv.ParseFloat(value, 64)
+			cell.LoadFactor, known = loadFactor, err == nil
+		case "size":
+			size, err := strconv.Atoi(value)
+			cell.Size, known = size, err == nil
+		default:
+			known = false
+		}
+
+		if !known {
+			if cell.Labels == nil {
+				cell.Labels = map[string]string{}
+			}
+			cell.Labels[key] = value
+		}
+	}
+
+	return cell
+}
+
+// splitVariant splits "Chain.SplitMix" into the method and its hasher.
+func splitVariant(variant string) (string, string) {
+	method, hasherName, _ := strings.Cut(variant, ".")
+	return method, hasherName
+}
+
+// Records turns results into one record per value. Lines repeated by
+// -count are numbered as repetitions in the order they were read.
+func Records(parsed []Result) []results.Record {
+	var (
+		records []results.Record
+		seen    = map[string]int{}
+	)
+
+	for _, result := range parsed {
+		cell := result.Cell()
+
+		repetition := seen[result.Name]
+		seen[result.Name]++
+
+		for _, v := range result.Values {
+			records = append(records, results.Record{
+				Experiment: result.Operation,
+				Method:     cell.Method,
+				Hasher:     cell.Hasher,
+				KeyKind:    cell.KeyKind,
+				LoadFactor: cell.LoadFactor,
+				Size:       cell.Size,
+				Metric:     v.Unit,
+				Value:      v.Value,
+				Unit:       v.Unit,
+				Repetition: repetition,
+				Labels:     cell.Labels,
+			})
+		}
+	}
+
+	return records
+}
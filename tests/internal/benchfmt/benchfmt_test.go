@@ -0,0 +1,100 @@
+package benchfmt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const output = `goos: linux
+goarch: amd64
+pkg: analyze/cmd/test
+cpu: Some CPU @ 2.00GHz
+BenchmarkInsertNoReserve/Chain-RandomKey-0.80-100-8         	     100	     24026 ns/op	       240.3 ns/insert	   13822 B/op	     301 allocs/op
+BenchmarkInsertNoReserve/Chain.SplitMix-RandomKey-0.80-100-8	     100	     24100 ns/op	       241.0 ns/insert	   13822 B/op	     301 allocs/op
+    bench_test.go:12: some log output
+BenchmarkSuccessGet/Double-SequentialKey-0.40-1000	 5000000	        25.12 ns/op
+BenchmarkSuccessGet/Double-SequentialKey-0.40-1000	 5000000	        25.27 ns/op
+BenchmarkDelete/method=Cuckoo/keys=RandomKey/lf=0.6/size=10/variant=x-4	    1000	        80.5 ns/op
+BenchmarkLegacy
+BenchmarkLegacy-4	      10	       100 ns/op
+PASS
+ok  	analyze/cmd/test	1.234s
+`
+
+func TestParse(t *testing.T) {
+	parsed, err := Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(parsed) != 6 {
+		t.Fatalf("got %d results, want 6", len(parsed))
+	}
+
+	first := parsed[0]
+	if first.Operation != "InsertNoReserve" || first.Sub != "Chain-RandomKey-0.80-100" || first.Procs != 8 || first.Iterations != 100 {
+		t.Errorf("unexpected first result: %+v", first)
+	}
+	if v, ok := first.Value("ns/insert"); !ok || v != 240.3 {
+		t.Errorf("ns/insert: got %v, %v", v, ok)
+	}
+	if v, ok := first.Value("B/op"); !ok || v != 13822 {
+		t.Errorf("B/op: got %v, %v", v, ok)
+	}
+	if first.Config["cpu"] != "Some CPU @ 2.00GHz" || first.Config["pkg"] != "analyze/cmd/test" {
+		t.Errorf("unexpected config: %v", first.Config)
+	}
+
+	tests := []struct {
+		result int
+		want   Cell
+	}{
+		{0, Cell{Method: "Chain", KeyKind: "RandomKey", LoadFactor: 0.8, Size: 100}},
+		{1, Cell{Method: "Chain", Hasher: "SplitMix", KeyKind: "RandomKey", LoadFactor: 0.8, Size: 100}},
+		// No -GOMAXPROCS suffix: the trailing number is the size.
+		{2, Cell{Method: "Double", KeyKind: "SequentialKey", LoadFactor: 0.4, Size: 1000}},
+		{4, Cell{Method: "Cuckoo", KeyKind: "RandomKey", LoadFactor: 0.6, Size: 10, Labels: map[string]string{"variant": "x"}}},
+		{5, Cell{}},
+	}
+
+	for _, tt := range tests {
+		if got := parsed[tt.result].Cell(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("result %d (%s): got %+v, want %+v", tt.result, parsed[tt.result].Name, got, tt.want)
+		}
+	}
+}
+
+func TestParseCellUnknownName(t *testing.T) {
+	got := ParseCell("some-other-name")
+	if got.Method != "" || got.Labels["name"] != "some-other-name" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	parsed, err := Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := Records(parsed)
+	if len(records) != 4+4+1+1+1+1 {
+		t.Fatalf("got %d records", len(records))
+	}
+
+	repeated := records[8:10]
+	if repeated[0].Repetition != 0 || repeated[1].Repetition != 1 || repeated[1].Value != 25.27 {
+		t.Errorf("repeated lines: %+v", repeated)
+	}
+
+	if r := records[1]; r.Experiment != "InsertNoReserve" || r.Metric != "ns/insert" || r.Value != 240.3 || r.Size != 100 {
+		t.Errorf("unexpected record: %+v", r)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	if _, err := Parse(strings.NewReader("BenchmarkX-4\t10\t12 ns/op\t7\n")); err == nil {
+		t.Error("expected an error for a value without unit")
+	}
+}
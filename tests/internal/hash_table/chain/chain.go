@@ -1,11 +1,12 @@
 package chain
 
 import (
+	"analyze/internal/hash_table/hasher"
+	"analyze/internal/hash_table/memory"
+	"analyze/internal/hash_table/resize"
 	"math/bits"
 )
 
-const hashConst uint64 = 0xbf58476d1ce4e5b9
-
 type entry struct {
 	key   int
 	value any
@@ -18,17 +19,44 @@ type HashTable struct {
 	loadFactor float64
 	probes     int
 	collisions int
+	hasher     hasher.Func
+	seed       uint64
+	observer   resize.Observer
 }
 
-func New(initialCapacity int) *HashTable {
+type Option func(*HashTable)
+
+func WithHasher(h hasher.Func) Option {
+	return func(ht *HashTable) {
+		if h != nil {
+			ht.hasher = h
+		}
+	}
+}
+
+// WithSeed seeds the hash of the bucket.
+func WithSeed(seed uint64) Option {
+	return func(ht *HashTable) {
+		ht.seed = seed
+	}
+}
+
+func New(initialCapacity int, opts ...Option) *HashTable {
 	capacity := nextPowerOfTwo(initialCapacity)
 
-	return &HashTable{
+	ht := &HashTable{
 		buckets:    make([][]entry, capacity),
 		size:       0,
 		cap:        capacity,
 		loadFactor: 1.,
+		hasher:     hasher.Multiplicative,
+	}
+
+	for _, opt := range opts {
+		opt(ht)
 	}
+
+	return ht
 }
 
 func (ht *HashTable) Insert(key int, value any) {
@@ -120,8 +148,36 @@ func (ht *HashTable) Capacity() int {
 	return ht.cap
 }
 
+func (ht *HashTable) OnResize(o resize.Observer) {
+	ht.observer = o
+}
+
+func (ht *HashTable) MemoryUsage() int {
+	bytes := memory.Slice(ht.buckets)
+
+	for _, bucket := range ht.buckets {
+		bytes += memory.Slice(bucket)
+		for _, e := range bucket {
+			bytes += memory.Value(e.value)
+		}
+	}
+
+	return bytes
+}
+
+func (ht *HashTable) BucketLengths() []int {
+	lengths := make([]int, len(ht.buckets))
+
+	for i, chain := range ht.buckets {
+		lengths[i] = len(chain)
+	}
+
+	return lengths
+}
+
 func (ht *HashTable) resize() {
-	old := ht.buckets
+	timer := ht.observer.Start(resize.LoadFactor, ht.cap)
+	old, moved := ht.buckets, ht.size
 	oldCollision := ht.collisions
 	capacity := ht.cap * 2
 
@@ -136,6 +192,7 @@ func (ht *HashTable) resize() {
 	}
 
 	ht.collisions = oldCollision
+	timer.Done(ht.cap, moved)
 }
 
 func (ht *HashTable) shouldResize() bool {
@@ -143,7 +200,7 @@ func (ht *HashTable) shouldResize() bool {
 }
 
 func (ht *HashTable) hash(key int) int {
-	return int((uint64(key) * hashConst) & uint64(ht.cap-1))
+	return int(ht.hasher(uint64(key), ht.seed) & uint64(ht.cap-1))
 }
 
 func nextPowerOfTwo(n int) int {
@@ -1,6 +1,10 @@
 package cuckoo
 
 import (
+	"analyze/internal/hash_table/hasher"
+	"analyze/internal/hash_table/layout"
+	"analyze/internal/hash_table/memory"
+	"analyze/internal/hash_table/resize"
 	"math/bits"
 	"math/rand"
 	"time"
@@ -26,9 +30,44 @@ type HashTable struct {
 	rehashCount  int
 	salt1, salt2 uint64
 	rng          *rand.Rand
+	hasher       hasher.Func
+	observer     resize.Observer
+	limit        resize.Limit
 }
 
-func New(initialCapacity int) *HashTable {
+type Option func(*HashTable)
+
+// WithHasher replaces the salted splitmix hash. The salts of the two tables
+// are passed to h as the seed.
+func WithHasher(h hasher.Func) Option {
+	return func(ht *HashTable) {
+		if h != nil {
+			ht.hasher = h
+		}
+	}
+}
+
+// WithSeed seeds the generator of the salts, which otherwise comes from the
+// clock, so the table and all of its rehashes are reproducible.
+func WithSeed(seed uint64) Option {
+	return func(ht *HashTable) {
+		ht.rng = rand.New(rand.NewSource(int64(seed)))
+	}
+}
+
+func WithMaxKicks(maxKicks int) Option {
+	return func(ht *HashTable) {
+		ht.maxKicks = maxKicks
+	}
+}
+
+func WithMaxRehashes(maxRehashes int) Option {
+	return func(ht *HashTable) {
+		ht.maxRehashes = maxRehashes
+	}
+}
+
+func New(initialCapacity int, opts ...Option) *HashTable {
 	if initialCapacity < 1 {
 		initialCapacity = 8
 	}
@@ -37,8 +76,7 @@ func New(initialCapacity int) *HashTable {
 	minPerTable := int(float64(initialCapacity)/(2*lf)) + 1
 	capacity := nextPowerOfTwo(minPerTable)
 
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-	return &HashTable{
+	ht := &HashTable{
 		table1:      make([]entry, capacity),
 		table2:      make([]entry, capacity),
 		capMask:     uint32(capacity - 1),
@@ -46,10 +84,21 @@ func New(initialCapacity int) *HashTable {
 		maxKicks:    500,
 		loadFactor:  lf,
 		maxRehashes: 5,
-		salt1:       rng.Uint64(),
-		salt2:       rng.Uint64(),
-		rng:         rng,
+		hasher:      hasher.SplitMix,
+	}
+
+	for _, opt := range opts {
+		opt(ht)
+	}
+
+	if ht.rng == nil {
+		ht.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
 	}
+
+	ht.salt1 = ht.rng.Uint64()
+	ht.salt2 = ht.rng.Uint64()
+
+	return ht
 }
 
 func (ht *HashTable) Insert(key int, value any) {
@@ -59,15 +108,19 @@ func (ht *HashTable) Insert(key int, value any) {
 
 	for {
 		if float64(ht.size+1) > ht.loadFactor*float64(2*len(ht.table1)) {
-			ht.resizeDouble()
+			if !ht.resizeDouble(resize.LoadFactor) {
+				return
+			}
 			ht.rehashCount = 0
 		}
 
-		if ht.insertOnce(newEntry, firstAttempt) {
+		homeless, ok := ht.insertOnce(newEntry, firstAttempt)
+		if ok {
 			ht.rehashCount = 0
 			return
 		}
 
+		newEntry = homeless
 		firstAttempt = false
 
 		if ht.rehashCount < ht.maxRehashes {
@@ -93,7 +146,9 @@ func (ht *HashTable) Insert(key int, value any) {
 			continue
 		}
 
-		ht.resizeDouble()
+		if !ht.resizeDouble(resize.RehashLimit) {
+			return
+		}
 		ht.rehashCount = 0
 	}
 }
@@ -163,7 +218,52 @@ func (ht *HashTable) Capacity() int {
 	return ht.cap
 }
 
-func (ht *HashTable) insertOnce(e entry, withCollision bool) bool {
+func (ht *HashTable) OnResize(o resize.Observer) {
+	ht.observer = o
+}
+
+func (ht *HashTable) LimitCapacity(capacity int) {
+	ht.limit.Set(capacity)
+}
+
+func (ht *HashTable) Stopped() bool {
+	return ht.limit.Stopped()
+}
+
+func (ht *HashTable) MemoryUsage() int {
+	bytes := memory.Slice(ht.table1) + memory.Slice(ht.table2)
+
+	for _, table := range [][]entry{ht.table1, ht.table2} {
+		for _, e := range table {
+			if e.occupied {
+				bytes += memory.Value(e.value)
+			}
+		}
+	}
+
+	return bytes
+}
+
+func (ht *HashTable) Snapshot() layout.Snapshot {
+	slots1 := make([]layout.Slot, len(ht.table1))
+	slots2 := make([]layout.Slot, len(ht.table2))
+
+	for idx, e := range ht.table1 {
+		if e.occupied {
+			slots1[idx] = layout.Slot{State: layout.Occupied, Home: idx}
+		}
+	}
+
+	for idx, e := range ht.table2 {
+		if e.occupied {
+			slots2[idx] = layout.Slot{State: layout.Occupied, Home: idx, Dist: 1}
+		}
+	}
+
+	return layout.Snapshot{Tables: [][]layout.Slot{slots1, slots2}, Scattered: true}
+}
+
+func (ht *HashTable) insertOnce(e entry, withCollision bool) (entry, bool) {
 	curKey, curVal := e.key, e.value
 	table := 0
 	for kick := 0; kick < ht.maxKicks; kick++ {
@@ -175,11 +275,11 @@ func (ht *HashTable) insertOnce(e entry, withCollision bool) bool {
 			if !slot.occupied {
 				slot.key, slot.value, slot.occupied = curKey, curVal, true
 				ht.size++
-				return true
+				return entry{}, true
 			}
 			if slot.key == curKey {
 				slot.value = curVal
-				return true
+				return entry{}, true
 			}
 
 			if kick == 0 {
@@ -198,11 +298,11 @@ func (ht *HashTable) insertOnce(e entry, withCollision bool) bool {
 			if !slot.occupied {
 				slot.key, slot.value, slot.occupied = curKey, curVal, true
 				ht.size++
-				return true
+				return entry{}, true
 			}
 			if slot.key == curKey {
 				slot.value = curVal
-				return true
+				return entry{}, true
 			}
 			slot.key, curKey = curKey, slot.key
 			slot.value, curVal = curVal, slot.value
@@ -210,10 +310,13 @@ func (ht *HashTable) insertOnce(e entry, withCollision bool) bool {
 
 		table ^= 1
 	}
-	return false
+	return entry{key: curKey, value: curVal, occupied: true}, false
 }
 
+// rehash is reported as a resize to the same capacity, failed when a key is
+// left without a place, with the keys placed until then as moved.
 func (ht *HashTable) rehash(all []entry) bool {
+	timer := ht.observer.Start(resize.FailedKicks, ht.cap)
 	newSalt1 := ht.rng.Uint64()
 	newSalt2 := ht.rng.Uint64()
 	n := len(ht.table1)
@@ -221,7 +324,7 @@ func (ht *HashTable) rehash(all []entry) bool {
 	t1 := make([]entry, n)
 	t2 := make([]entry, n)
 
-	for _, e := range all {
+	for i, e := range all {
 		curKey, curVal := e.key, e.value
 		table := 0
 		placed := false
@@ -229,7 +332,7 @@ func (ht *HashTable) rehash(all []entry) bool {
 			ht.probes++
 
 			if table == 0 {
-				idx := uint32(splitmix(uint64(curKey)^newSalt1)) & uint32(n-1)
+				idx := uint32(ht.hasher(uint64(curKey), newSalt1)) & uint32(n-1)
 				slot := &t1[idx]
 				if !slot.occupied {
 					slot.key, slot.value, slot.occupied = curKey, curVal, true
@@ -244,7 +347,7 @@ func (ht *HashTable) rehash(all []entry) bool {
 				slot.key, curKey = curKey, slot.key
 				slot.value, curVal = curVal, slot.value
 			} else {
-				idx := uint32(splitmix(uint64(curKey)^newSalt2)) & uint32(n-1)
+				idx := uint32(ht.hasher(uint64(curKey), newSalt2)) & uint32(n-1)
 				slot := &t2[idx]
 				if !slot.occupied {
 					slot.key, slot.value, slot.occupied = curKey, curVal, true
@@ -262,6 +365,7 @@ func (ht *HashTable) rehash(all []entry) bool {
 			table ^= 1
 		}
 		if !placed {
+			timer.Failed(ht.cap, i)
 			return false
 		}
 	}
@@ -271,10 +375,17 @@ func (ht *HashTable) rehash(all []entry) bool {
 	ht.salt1 = newSalt1
 	ht.salt2 = newSalt2
 	ht.size = len(all)
+	timer.Done(ht.cap, len(all))
 	return true
 }
 
-func (ht *HashTable) resizeDouble() {
+// resizeDouble reports false when the capacity limit stops the table.
+func (ht *HashTable) resizeDouble(reason resize.Reason) bool {
+	if !ht.limit.Allows(len(ht.table1) * 4) {
+		return false
+	}
+
+	timer := ht.observer.Start(reason, ht.cap)
 	old := make([]entry, 0, ht.size)
 	for _, e := range ht.table1 {
 		if e.occupied {
@@ -292,24 +403,32 @@ func (ht *HashTable) resizeDouble() {
 	ht.table1 = make([]entry, newCap)
 	ht.table2 = make([]entry, newCap)
 	ht.capMask = uint32(newCap - 1)
+	ht.cap = newCap
 	ht.size = 0
+
+	var homeless []entry
 	for _, e := range old {
-		ht.insertOnce(e, false)
+		if e, ok := ht.insertOnce(e, false); !ok {
+			homeless = append(homeless, e)
+		}
 	}
+
+	timer.Done(ht.cap, len(old))
+
+	// A key kicked out of the last move is inserted again, which rehashes or
+	// grows the table once more if it has to.
+	for _, e := range homeless {
+		ht.Insert(e.key, e.value)
+	}
+
+	return true
 }
 
 func (ht *HashTable) hash1(key int) uint32 {
-	return uint32(uint64(key)*ht.salt1) & ht.capMask
+	return uint32(ht.hasher(uint64(key), ht.salt1)) & ht.capMask
 }
 func (ht *HashTable) hash2(key int) uint32 {
-	return uint32(uint64(key)*ht.salt2) & ht.capMask
-}
-
-func splitmix(x uint64) uint64 {
-	x += 0x9e3779b97f4a7c15
-	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
-	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
-	return x ^ (x >> 31)
+	return uint32(ht.hasher(uint64(key), ht.salt2)) & ht.capMask
 }
 
 func nextPowerOfTwo(n int) int {
@@ -1,8 +1,12 @@
 package double
 
-import "math/bits"
-
-const hashConst uint64 = 0xbf58476d1ce4e5b9
+import (
+	"analyze/internal/hash_table/hasher"
+	"analyze/internal/hash_table/layout"
+	"analyze/internal/hash_table/memory"
+	"analyze/internal/hash_table/resize"
+	"math/bits"
+)
 
 type entry struct {
 	key   int
@@ -17,27 +21,58 @@ type HashTable struct {
 	loadFactor float64
 	probes     int
 	collisions int
+	hasher     hasher.Func
+	seed       uint64
+	observer   resize.Observer
+	limit      resize.Limit
+}
+
+type Option func(*HashTable)
+
+func WithHasher(h hasher.Func) Option {
+	return func(ht *HashTable) {
+		if h != nil {
+			ht.hasher = h
+		}
+	}
 }
 
-func New(initialCapacity int) *HashTable {
+// WithSeed seeds the hash of the home slot; the probe step does not use it.
+func WithSeed(seed uint64) Option {
+	return func(ht *HashTable) {
+		ht.seed = seed
+	}
+}
+
+func New(initialCapacity int, opts ...Option) *HashTable {
 	capacity := nextPowerOfTwo(initialCapacity)
 
-	return &HashTable{
+	ht := &HashTable{
 		table:      make([]entry, capacity),
 		size:       0,
 		cap:        capacity,
 		loadFactor: 0.7,
+		hasher:     hasher.Multiplicative,
 	}
+
+	for _, opt := range opts {
+		opt(ht)
+	}
+
+	return ht
 }
 
 func (ht *HashTable) Insert(key int, value any) {
 	if ht.shouldResize() {
-		ht.resize()
+		ht.resize(resize.LoadFactor)
+	}
+	if ht.limit.Stopped() {
+		return
 	}
 
 	ok := ht.insertNoResize(key, value, true)
 	if !ok {
-		if !ht.resize() {
+		if !ht.resize(resize.ProbeExhaustion) {
 			return
 		}
 
@@ -169,7 +204,61 @@ func (ht *HashTable) Capacity() int {
 	return ht.cap
 }
 
-func (ht *HashTable) resize() bool {
+func (ht *HashTable) OnResize(o resize.Observer) {
+	ht.observer = o
+}
+
+func (ht *HashTable) LimitCapacity(capacity int) {
+	ht.limit.Set(capacity)
+}
+
+func (ht *HashTable) Stopped() bool {
+	return ht.limit.Stopped()
+}
+
+func (ht *HashTable) MemoryUsage() int {
+	bytes := memory.Slice(ht.table)
+
+	for _, e := range ht.table {
+		if e.state == 1 {
+			bytes += memory.Value(e.value)
+		}
+	}
+
+	return bytes
+}
+
+func (ht *HashTable) Snapshot() layout.Snapshot {
+	slots := make([]layout.Slot, ht.cap)
+
+	for idx, e := range ht.table {
+		switch e.state {
+		case 1:
+			h1 := ht.hash1(e.key)
+			h2 := ht.hash2(e.key)
+			dist := 0
+
+			for (h1+dist*h2)&(ht.cap-1) != idx {
+				dist++
+			}
+
+			slots[idx] = layout.Slot{State: layout.Occupied, Home: h1, Dist: dist}
+		case 2:
+			slots[idx] = layout.Slot{State: layout.Tombstone}
+		}
+	}
+
+	return layout.Snapshot{Tables: [][]layout.Slot{slots}}
+}
+
+// resize reports false when it fails to place a key or the capacity limit
+// stops the table.
+func (ht *HashTable) resize(reason resize.Reason) bool {
+	if !ht.limit.Allows(ht.cap * 2) {
+		return false
+	}
+
+	timer := ht.observer.Start(reason, ht.cap)
 	old := ht.table
 	capacity := ht.cap * 2
 
@@ -181,11 +270,13 @@ func (ht *HashTable) resize() bool {
 		if e.state == 1 {
 			ok := ht.insertNoResize(e.key, e.value, false)
 			if !ok {
+				timer.Failed(ht.cap, ht.size)
 				return false
 			}
 		}
 	}
 
+	timer.Done(ht.cap, ht.size)
 	return true
 }
 
@@ -194,7 +285,7 @@ func (ht *HashTable) shouldResize() bool {
 }
 
 func (ht *HashTable) hash1(key int) int {
-	return int((uint64(key) * hashConst) & uint64(ht.cap-1))
+	return int(ht.hasher(uint64(key), ht.seed) & uint64(ht.cap-1))
 }
 
 func (ht *HashTable) hash2(key int) int {
@@ -4,9 +4,14 @@ import (
 	"analyze/internal/hash_table/chain"
 	"analyze/internal/hash_table/cuckoo"
 	double "analyze/internal/hash_table/double_hash"
+	"analyze/internal/hash_table/hasher"
 	"analyze/internal/hash_table/hopscotch"
+	"analyze/internal/hash_table/layout"
+	"analyze/internal/hash_table/memory"
+	"analyze/internal/hash_table/resize"
 	robinhood "analyze/internal/hash_table/robin_hood"
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -125,3 +130,215 @@ func TestSize(t *testing.T) {
 		})
 	}
 }
+
+func TestSeedReproducible(t *testing.T) {
+	build := func(seed uint64) *cuckoo.HashTable {
+		ht := cuckoo.New(8, cuckoo.WithSeed(seed))
+		for i := 0; i < 10000; i++ {
+			ht.Insert(i, i)
+		}
+		return ht
+	}
+
+	first, second := build(42).Snapshot(), build(42).Snapshot()
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("cuckoo tables with the same seed have different layouts")
+	}
+
+	if reflect.DeepEqual(first, build(43).Snapshot()) {
+		t.Errorf("cuckoo tables with different seeds have the same layout")
+	}
+}
+
+// TestHopscotchFarNeighbours fills one home bucket past 32 entries, so keys
+// sit in the upper half of its 64-slot neighbourhood.
+func TestHopscotchFarNeighbours(t *testing.T) {
+	ht := hopscotch.New(1024)
+
+	// Under Multiplicative, keys that share their low ten bits share a home.
+	for i := 0; i < 48; i++ {
+		ht.Insert(i<<10, i)
+	}
+
+	for i := 0; i < 48; i++ {
+		if v, ok := ht.Get(i << 10); !ok || v != i {
+			t.Errorf("key %d at distance %d: got %v, %v", i<<10, i, v, ok)
+		}
+	}
+}
+
+// Keys i<<12 crowd one neighbourhood, so the table grows until they separate.
+func TestHopscotchGrowsApart(t *testing.T) {
+	ht := hopscotch.New(1024)
+
+	for i := 0; i < 100; i++ {
+		ht.Insert(i<<12, i)
+	}
+
+	if ht.Capacity() <= 1024 {
+		t.Errorf("capacity %d, want the table to grow", ht.Capacity())
+	}
+	for i := 0; i < 100; i++ {
+		if v, ok := ht.Get(i << 12); !ok || v != i {
+			t.Errorf("Get(%d) = %v, %v", i<<12, v, ok)
+		}
+	}
+}
+
+// The table grows until the keys separate, and a key that finds no place
+// while the table is moved is inserted again, not dropped.
+func TestCuckooKeepsKeysOnResize(t *testing.T) {
+	ht := cuckoo.New(8, cuckoo.WithHasher(hasher.Multiplicative), cuckoo.WithSeed(1))
+
+	for i := 0; i < 20; i++ {
+		ht.Insert(i<<8, i)
+	}
+
+	if ht.Size() != 20 {
+		t.Errorf("size %d, want 20", ht.Size())
+	}
+	for i := 0; i < 20; i++ {
+		if v, ok := ht.Get(i << 8); !ok || v != i {
+			t.Errorf("Get(%d) = %v, %v", i<<8, v, ok)
+		}
+	}
+}
+
+// Under Multiplicative, keys i<<40 share their home at any capacity, so the
+// tables grow on every insert until their capacity limit stops them.
+func TestCapacityLimit(t *testing.T) {
+	tables := map[string]HashTable{
+		"Cuckoo":    cuckoo.New(8, cuckoo.WithHasher(hasher.Multiplicative), cuckoo.WithSeed(1)),
+		"Hopscotch": hopscotch.New(8),
+	}
+
+	for name, ht := range tables {
+		limited := ht.(resize.Limited)
+		limited.LimitCapacity(1 << 12)
+
+		for i := 0; i < 200 && !limited.Stopped(); i++ {
+			ht.Insert(i<<40, i)
+		}
+
+		if !limited.Stopped() {
+			t.Errorf("%s: not stopped at capacity %d", name, ht.Capacity())
+		}
+		if ht.Capacity() > 1<<12 {
+			t.Errorf("%s: capacity %d past the limit", name, ht.Capacity())
+		}
+	}
+}
+
+func TestSeedMixing(t *testing.T) {
+	type build func(h hasher.Func, seed uint64) HashTable
+
+	tables := map[string]build{
+		"Chain": func(h hasher.Func, s uint64) HashTable {
+			return chain.New(1024, chain.WithHasher(h), chain.WithSeed(s))
+		},
+		"Double": func(h hasher.Func, s uint64) HashTable {
+			return double.New(1024, double.WithHasher(h), double.WithSeed(s))
+		},
+		"Hopscotch": func(h hasher.Func, s uint64) HashTable {
+			return hopscotch.New(1024, hopscotch.WithHasher(h), hopscotch.WithSeed(s))
+		},
+		"RobinHood": func(h hasher.Func, s uint64) HashTable {
+			return robinhood.New(1024, robinhood.WithHasher(h), robinhood.WithSeed(s))
+		},
+	}
+
+	placement := func(ht HashTable) any {
+		if c, ok := ht.(*chain.HashTable); ok {
+			return c.BucketLengths()
+		}
+		return ht.(layout.Inspector).Snapshot()
+	}
+
+	fill := func(ht HashTable) HashTable {
+		for i := 0; i < 500; i++ {
+			ht.Insert(i, i)
+		}
+		return ht
+	}
+
+	for name, newTable := range tables {
+		t.Run(name, func(t *testing.T) {
+			if reflect.DeepEqual(placement(fill(newTable(hasher.SplitMix, 1<<40))), placement(fill(newTable(hasher.SplitMix, 1<<41)))) {
+				t.Errorf("SplitMix tables with different seeds place the keys the same way")
+			}
+		})
+	}
+
+	// Keys that differ only above the bits of the capacity keep sharing
+	// their home under Multiplicative, whatever the seed.
+	for _, seed := range []uint64{0, 1, 0xdeadbeef} {
+		a := hasher.Multiplicative(5, seed) & 1023
+		b := hasher.Multiplicative(5+1<<20, seed) & 1023
+		if a != b {
+			t.Errorf("seed %#x separated keys that share their low bits", seed)
+		}
+	}
+}
+
+func TestResizeObserver(t *testing.T) {
+	for name, newTable := range factoryMap() {
+		t.Run(name, func(t *testing.T) {
+			ht := newTable(8)
+
+			var events []resize.Event
+			ht.(resize.Observable).OnResize(func(e resize.Event) {
+				events = append(events, e)
+			})
+
+			for i := 0; i < 1000; i++ {
+				ht.Insert(i, i)
+			}
+
+			if len(events) == 0 {
+				t.Fatal("no resize reported")
+			}
+
+			for _, e := range events {
+				switch e.Reason {
+				case resize.FailedKicks:
+					if e.NewCapacity != e.OldCapacity {
+						t.Errorf("rehash from %d to %d", e.OldCapacity, e.NewCapacity)
+					}
+				case resize.LoadFactor, resize.MaxDistance, resize.RehashLimit:
+					if e.NewCapacity <= e.OldCapacity || e.Failed {
+						t.Errorf("%s resize from %d to %d, failed %v", e.Reason, e.OldCapacity, e.NewCapacity, e.Failed)
+					}
+				default:
+					t.Errorf("unexpected reason %q", e.Reason)
+				}
+			}
+
+			if last := events[len(events)-1]; last.NewCapacity != ht.Capacity() {
+				t.Errorf("last resize to %d, capacity %d", last.NewCapacity, ht.Capacity())
+			}
+		})
+	}
+}
+
+func TestMemoryUsage(t *testing.T) {
+	for name, newTable := range factoryMap() {
+		t.Run(name, func(t *testing.T) {
+			ht := newTable(8)
+			reporter := ht.(memory.Reporter)
+
+			empty := reporter.MemoryUsage()
+			if empty <= 0 {
+				t.Fatalf("empty table uses %d bytes", empty)
+			}
+
+			for i := 0; i < 1000; i++ {
+				ht.Insert(i, i)
+			}
+
+			// Every entry holds at least its key, an interface and the boxed int.
+			if used, least := reporter.MemoryUsage(), 1000*(8+16+8); used < least {
+				t.Errorf("1000 entries use %d bytes, want at least %d", used, least)
+			}
+		})
+	}
+}
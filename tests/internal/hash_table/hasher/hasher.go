@@ -0,0 +1,27 @@
+package hasher
+
+// Func maps a key to a 64-bit hash. Tables keep the low bits of the result,
+// the seed lets a table (or cuckoo's salts) pick a member of the family.
+type Func func(key, seed uint64) uint64
+
+const multiplier uint64 = 0xbf58476d1ce4e5b9
+
+// Multiplicative is the hash the tables have always used. Its low bits only
+// depend on the low bits of the key, which makes it fast and weak. The seed
+// is only xored into the key, so it does not pick an independent function:
+// keys that share their low bits collide under every seed, and tables with
+// different seeds are only independent under a mixing hasher such as
+// SplitMix. With seed 0 it is the original key*multiplier.
+func Multiplicative(key, seed uint64) uint64 {
+	return (key ^ seed) * multiplier
+}
+
+// SplitMix is the splitmix64 finalizer: every bit of the key affects every bit
+// of the result.
+func SplitMix(key, seed uint64) uint64 {
+	x := key ^ seed
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
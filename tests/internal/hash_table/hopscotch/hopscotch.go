@@ -1,13 +1,16 @@
 package hopscotch
 
 import (
+	"analyze/internal/hash_table/hasher"
+	"analyze/internal/hash_table/layout"
+	"analyze/internal/hash_table/memory"
+	"analyze/internal/hash_table/resize"
 	"math/bits"
 )
 
 const (
-	hashConst         uint64 = 0xbf58476d1ce4e5b9
-	neighbourhoodSize        = 64
-	maxDistance              = 256
+	neighbourhoodSize = 64
+	maxDistance       = 256
 )
 
 type entry struct {
@@ -18,31 +21,59 @@ type entry struct {
 
 type HashTable struct {
 	buckets       []entry
-	hopInfo       []uint32
+	hopInfo       []uint64
 	size          int
 	cap           int
 	loadFactor    float64
 	probes        int
 	collisions    int
 	withCollision bool
+	hasher        hasher.Func
+	seed          uint64
+	observer      resize.Observer
+	limit         resize.Limit
 }
 
-func New(initialCapacity int) *HashTable {
+type Option func(*HashTable)
+
+func WithHasher(h hasher.Func) Option {
+	return func(ht *HashTable) {
+		if h != nil {
+			ht.hasher = h
+		}
+	}
+}
+
+// WithSeed seeds the hash of the home bucket.
+func WithSeed(seed uint64) Option {
+	return func(ht *HashTable) {
+		ht.seed = seed
+	}
+}
+
+func New(initialCapacity int, opts ...Option) *HashTable {
 	capacity := nextPowerOfTwo(initialCapacity)
 
-	return &HashTable{
+	ht := &HashTable{
 		buckets:       make([]entry, capacity),
-		hopInfo:       make([]uint32, capacity),
+		hopInfo:       make([]uint64, capacity),
 		size:          0,
 		cap:           capacity,
 		loadFactor:    1,
 		withCollision: true,
+		hasher:        hasher.Multiplicative,
+	}
+
+	for _, opt := range opts {
+		opt(ht)
 	}
+
+	return ht
 }
 
 func (ht *HashTable) Insert(key int, value any) {
-	if ht.shouldResize() {
-		ht.resize()
+	if ht.shouldResize() && !ht.resize(resize.LoadFactor) {
+		return
 	}
 
 	base := ht.hash(key)
@@ -51,7 +82,7 @@ func (ht *HashTable) Insert(key int, value any) {
 	hop := ht.hopInfo[base]
 
 	for hop != 0 {
-		offset := bits.TrailingZeros32(hop)
+		offset := bits.TrailingZeros64(hop)
 		idx := (base + offset) & (ht.cap - 1)
 		ht.probes++
 
@@ -83,9 +114,7 @@ func (ht *HashTable) Insert(key int, value any) {
 	}
 
 	if dist == maxDistance {
-		ht.resize()
-		ht.Insert(key, value)
-		ht.withCollision = true
+		ht.grow(key, value)
 
 		return
 	}
@@ -111,9 +140,7 @@ func (ht *HashTable) Insert(key int, value any) {
 		}
 
 		if !moved {
-			ht.resize()
-			ht.Insert(key, value)
-			ht.withCollision = true
+			ht.grow(key, value)
 
 			return
 		}
@@ -131,7 +158,7 @@ func (ht *HashTable) Get(key int) (any, bool) {
 	for hop != 0 {
 		ht.probes++
 
-		offset := bits.TrailingZeros32(hop)
+		offset := bits.TrailingZeros64(hop)
 		idx := (base + offset) & (ht.cap - 1)
 
 		if ht.buckets[idx].inUse && ht.buckets[idx].key == key {
@@ -155,7 +182,7 @@ func (ht *HashTable) Delete(key int) {
 	for hop != 0 {
 		ht.probes++
 
-		offset := bits.TrailingZeros32(hop)
+		offset := bits.TrailingZeros64(hop)
 		idx := (base + offset) & (ht.cap - 1)
 
 		if ht.buckets[idx].inUse && ht.buckets[idx].key == key {
@@ -198,13 +225,58 @@ func (ht *HashTable) Capacity() int {
 	return ht.cap
 }
 
-func (ht *HashTable) resize() {
-	old := ht.buckets
+func (ht *HashTable) OnResize(o resize.Observer) {
+	ht.observer = o
+}
+
+func (ht *HashTable) LimitCapacity(capacity int) {
+	ht.limit.Set(capacity)
+}
+
+func (ht *HashTable) Stopped() bool {
+	return ht.limit.Stopped()
+}
+
+func (ht *HashTable) MemoryUsage() int {
+	bytes := memory.Slice(ht.buckets) + memory.Slice(ht.hopInfo)
+
+	for _, e := range ht.buckets {
+		if e.inUse {
+			bytes += memory.Value(e.value)
+		}
+	}
+
+	return bytes
+}
+
+func (ht *HashTable) Snapshot() layout.Snapshot {
+	slots := make([]layout.Slot, ht.cap)
+
+	for idx, e := range ht.buckets {
+		if e.inUse {
+			home := ht.hash(e.key)
+			slots[idx] = layout.Slot{State: layout.Occupied, Home: home, Dist: (idx - home) & (ht.cap - 1)}
+		}
+	}
+
+	return layout.Snapshot{Tables: [][]layout.Slot{slots}}
+}
+
+// resize reinserts through Insert, so a key that still finds no room grows
+// the table again; those resizes are reported before this one. It reports
+// false when the capacity limit stops the table instead.
+func (ht *HashTable) resize(reason resize.Reason) bool {
+	if !ht.limit.Allows(ht.cap * 2) {
+		return false
+	}
+
+	timer := ht.observer.Start(reason, ht.cap)
+	old, moved := ht.buckets, ht.size
 	oldCollision := ht.collisions
 	capacity := ht.cap * 2
 
 	ht.buckets = make([]entry, capacity)
-	ht.hopInfo = make([]uint32, capacity)
+	ht.hopInfo = make([]uint64, capacity)
 	ht.size = 0
 	ht.cap = capacity
 
@@ -215,10 +287,24 @@ func (ht *HashTable) resize() {
 	}
 
 	ht.collisions = oldCollision
+	timer.Done(ht.cap, moved)
+
+	return true
+}
+
+// grow handles a key that found no slot in its neighbourhood by doubling the
+// table.
+func (ht *HashTable) grow(key int, value any) {
+	if !ht.resize(resize.MaxDistance) {
+		return
+	}
+
+	ht.Insert(key, value)
+	ht.withCollision = true
 }
 
 func (ht *HashTable) hash(key int) int {
-	return int((uint64(key) * hashConst) & uint64(ht.cap-1))
+	return int(ht.hasher(uint64(key), ht.seed) & uint64(ht.cap-1))
 }
 
 func (ht *HashTable) shouldResize() bool {
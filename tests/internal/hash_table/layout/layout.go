@@ -0,0 +1,33 @@
+package layout
+
+type State uint8
+
+const (
+	Empty State = iota
+	Occupied
+	Tombstone
+)
+
+// Slot describes a single cell of an open-addressing array. Home and Dist are
+// only meaningful for occupied slots: Home is the index the key hashes to and
+// Dist is the number of probe steps the key sits away from it.
+type Slot struct {
+	State State
+	Home  int
+	Dist  int
+}
+
+// Snapshot is a copy of the internal arrays of a table. Most methods have a
+// single array, cuckoo hashing exposes both of its tables.
+//
+// Scattered is set by tables that do not place keys along a probe sequence,
+// such as cuckoo hashing: runs of slots say nothing about lookup cost, and
+// Dist is the number of the table a key sits in, 0 for its first choice.
+type Snapshot struct {
+	Tables    [][]Slot
+	Scattered bool
+}
+
+type Inspector interface {
+	Snapshot() Snapshot
+}
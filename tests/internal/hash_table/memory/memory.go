@@ -0,0 +1,39 @@
+// Package memory estimates the bytes a table holds on to, from the sizes of
+// its arrays rather than from what the allocator handed out.
+package memory
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Reporter tables count their slot arrays, metadata, chains, overflow lists
+// and the values boxed in their slots. The counts use the capacity of every
+// slice, not its length, and leave out the rounding of the allocator.
+type Reporter interface {
+	MemoryUsage() int
+}
+
+// Slice is the backing array of s.
+func Slice[T any](s []T) int {
+	var zero T
+	return cap(s) * int(unsafe.Sizeof(zero))
+}
+
+// Value is what a value stored as any points to. The interface itself is
+// part of the slot. Small integers and zero-sized values are not allocated
+// by the runtime, but are still counted at their size.
+func Value(v any) int {
+	switch v := v.(type) {
+	case nil:
+		return 0
+	case int:
+		return int(unsafe.Sizeof(v))
+	case string:
+		return int(unsafe.Sizeof(v)) + len(v)
+	case []byte:
+		return int(unsafe.Sizeof(v)) + cap(v)
+	default:
+		return int(reflect.TypeOf(v).Size())
+	}
+}
@@ -0,0 +1,112 @@
+// Package resize describes the resizes and rehashes of a table to an
+// observer, so their timing and causes can be traced.
+package resize
+
+import "time"
+
+// Reason is what made a table resize or rehash.
+type Reason string
+
+const (
+	// LoadFactor: the table reached its maximum load factor.
+	LoadFactor Reason = "load_factor"
+	// MaxDistance: hopscotch found no free slot it could move into the
+	// neighbourhood of the key.
+	MaxDistance Reason = "max_distance"
+	// FailedKicks: cuckoo ran out of kicks and rehashes with new salts at
+	// the same capacity.
+	FailedKicks Reason = "failed_kicks"
+	// RehashLimit: cuckoo ran out of rehashes and grows instead.
+	RehashLimit Reason = "rehash_limit"
+	// ProbeExhaustion: double hashing probed its whole sequence without
+	// finding a free slot.
+	ProbeExhaustion Reason = "probe_exhaustion"
+)
+
+var Reasons = []Reason{LoadFactor, MaxDistance, FailedKicks, RehashLimit, ProbeExhaustion}
+
+// Event is one resize or rehash. Capacities are as Capacity reports them,
+// Moved counts the entries placed again. A failed rehash is reported too.
+type Event struct {
+	Reason      Reason
+	OldCapacity int
+	NewCapacity int
+	Moved       int
+	Duration    time.Duration
+	Failed      bool
+}
+
+type Observer func(Event)
+
+// Observable tables report every resize to the observer set last.
+type Observable interface {
+	OnResize(o Observer)
+}
+
+// Timer times one resize for its observer. Without an observer it does
+// nothing, so the tables can use it unconditionally.
+type Timer struct {
+	observer Observer
+	start    time.Time
+	event    Event
+}
+
+func (o Observer) Start(reason Reason, oldCapacity int) Timer {
+	if o == nil {
+		return Timer{}
+	}
+
+	return Timer{observer: o, start: time.Now(), event: Event{Reason: reason, OldCapacity: oldCapacity}}
+}
+
+func (t Timer) Done(newCapacity int, moved int) {
+	t.finish(newCapacity, moved, false)
+}
+
+func (t Timer) Failed(newCapacity int, moved int) {
+	t.finish(newCapacity, moved, true)
+}
+
+func (t Timer) finish(newCapacity int, moved int, failed bool) {
+	if t.observer == nil {
+		return
+	}
+
+	t.event.NewCapacity, t.event.Moved, t.event.Failed = newCapacity, moved, failed
+	t.event.Duration = time.Since(t.start)
+	t.observer(t.event)
+}
+
+// Limited tables stop growing at a capacity their caller sets. An insert
+// that would need a bigger table leaves its key out, and every resize is
+// refused from then on. Keys a resize under way was moving may be lost as
+// well, so a stopped table is only good to be dropped. A capacity of 0
+// lifts the limit.
+type Limited interface {
+	LimitCapacity(capacity int)
+	Stopped() bool
+}
+
+// Limit is the capacity limit of a table. The zero Limit allows any capacity.
+type Limit struct {
+	capacity int
+	stopped  bool
+}
+
+func (l *Limit) Set(capacity int) {
+	l.capacity = capacity
+}
+
+// Allows reports whether the table may grow to capacity, and stops it for
+// good if not.
+func (l *Limit) Allows(capacity int) bool {
+	if l.capacity > 0 && capacity > l.capacity {
+		l.stopped = true
+	}
+
+	return !l.stopped
+}
+
+func (l *Limit) Stopped() bool {
+	return l.stopped
+}
@@ -1,6 +1,10 @@
 package robinhood
 
 import (
+	"analyze/internal/hash_table/hasher"
+	"analyze/internal/hash_table/layout"
+	"analyze/internal/hash_table/memory"
+	"analyze/internal/hash_table/resize"
 	"math/bits"
 )
 
@@ -12,10 +16,6 @@ const (
 	tomb
 )
 
-const (
-	hashConst uint64 = 0xbf58476d1ce4e5b9
-)
-
 type bucket struct {
 	key   int
 	value any
@@ -29,17 +29,44 @@ type HashTable struct {
 	loadFactor float64
 	probes     int
 	collisions int
+	hasher     hasher.Func
+	seed       uint64
+	observer   resize.Observer
+}
+
+type Option func(*HashTable)
+
+func WithHasher(h hasher.Func) Option {
+	return func(ht *HashTable) {
+		if h != nil {
+			ht.hasher = h
+		}
+	}
 }
 
-func New(initialCapacity int) *HashTable {
+// WithSeed seeds the hash of the home slot.
+func WithSeed(seed uint64) Option {
+	return func(ht *HashTable) {
+		ht.seed = seed
+	}
+}
+
+func New(initialCapacity int, opts ...Option) *HashTable {
 	capacity := nextPowerOfTwo(initialCapacity)
 
-	return &HashTable{
+	ht := &HashTable{
 		table:      make([]bucket, capacity),
 		size:       0,
 		cap:        capacity,
 		loadFactor: 0.7,
+		hasher:     hasher.Multiplicative,
+	}
+
+	for _, opt := range opts {
+		opt(ht)
 	}
+
+	return ht
 }
 
 func (ht *HashTable) Insert(key int, value any) {
@@ -177,8 +204,41 @@ func (ht *HashTable) Capacity() int {
 	return ht.cap
 }
 
+func (ht *HashTable) OnResize(o resize.Observer) {
+	ht.observer = o
+}
+
+func (ht *HashTable) MemoryUsage() int {
+	bytes := memory.Slice(ht.table)
+
+	for _, b := range ht.table {
+		if b.flag == occupied {
+			bytes += memory.Value(b.value)
+		}
+	}
+
+	return bytes
+}
+
+func (ht *HashTable) Snapshot() layout.Snapshot {
+	slots := make([]layout.Slot, ht.cap)
+
+	for idx, b := range ht.table {
+		switch b.flag {
+		case occupied:
+			home := ht.hash(b.key)
+			slots[idx] = layout.Slot{State: layout.Occupied, Home: home, Dist: (idx - home) & (ht.cap - 1)}
+		case tomb:
+			slots[idx] = layout.Slot{State: layout.Tombstone}
+		}
+	}
+
+	return layout.Snapshot{Tables: [][]layout.Slot{slots}}
+}
+
 func (ht *HashTable) resize() {
-	old := ht.table
+	timer := ht.observer.Start(resize.LoadFactor, ht.cap)
+	old, moved := ht.table, ht.size
 	oldCollisions := ht.collisions
 	capacity := ht.cap * 2
 
@@ -193,10 +253,11 @@ func (ht *HashTable) resize() {
 	}
 
 	ht.collisions = oldCollisions
+	timer.Done(ht.cap, moved)
 }
 
 func (ht *HashTable) hash(key int) int {
-	return int((uint64(key) * hashConst) & uint64(ht.cap-1))
+	return int(ht.hasher(uint64(key), ht.seed) & uint64(ht.cap-1))
 }
 
 func (ht *HashTable) shouldResize() bool {
@@ -0,0 +1,143 @@
+// Package hdr is a histogram of non-negative integer values, such as
+// latencies in nanoseconds, in the manner of an HDR histogram: buckets are
+// exact for small values and grow with the value, so every value is kept to
+// a fixed relative precision over the whole range, in constant memory.
+package hdr
+
+import (
+	"math"
+	"math/bits"
+)
+
+// subBits sets the precision: values below 2^subBits are counted exactly,
+// larger ones to within 2^-(subBits-1), below 1%.
+const (
+	subBits  = 8
+	subCount = 1 << subBits
+	halfSub  = subCount / 2
+)
+
+type Histogram struct {
+	counts []uint64
+	total  uint64
+	sum    float64
+	min    int64
+	max    int64
+}
+
+func New() *Histogram {
+	return &Histogram{min: math.MaxInt64}
+}
+
+// Record counts one value. Negative values count as zero.
+func (h *Histogram) Record(v int64) {
+	h.RecordN(v, 1)
+}
+
+// RecordN counts n occurrences of v, e.g. the mean of a batch of n timed
+// together.
+func (h *Histogram) RecordN(v int64, n uint64) {
+	if n == 0 {
+		return
+	}
+
+	v = max(v, 0)
+
+	i := index(v)
+	if i >= len(h.counts) {
+		h.counts = append(h.counts, make([]uint64, i+1-len(h.counts))...)
+	}
+
+	h.counts[i] += n
+	h.total += n
+	h.sum += float64(v) * float64(n)
+	h.min = min(h.min, v)
+	h.max = max(h.max, v)
+}
+
+// Merge adds the counts of o to h.
+func (h *Histogram) Merge(o *Histogram) {
+	if len(o.counts) > len(h.counts) {
+		h.counts = append(h.counts, make([]uint64, len(o.counts)-len(h.counts))...)
+	}
+
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+
+	h.total += o.total
+	h.sum += o.sum
+	h.min = min(h.min, o.min)
+	h.max = max(h.max, o.max)
+}
+
+func (h *Histogram) Count() uint64 {
+	return h.total
+}
+
+func (h *Histogram) Mean() float64 {
+	if h.total == 0 {
+		return 0
+	}
+
+	return h.sum / float64(h.total)
+}
+
+func (h *Histogram) Min() int64 {
+	if h.total == 0 {
+		return 0
+	}
+
+	return h.min
+}
+
+func (h *Histogram) Max() int64 {
+	return h.max
+}
+
+// Quantile returns the value at or below which a share q of the counts
+// lie, as the highest value of its bucket, never above the largest value
+// recorded.
+func (h *Histogram) Quantile(q float64) int64 {
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(min(max(q, 0), 1) * float64(h.total)))
+	rank = max(rank, 1)
+
+	var seen uint64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			return min(upper(i), h.max)
+		}
+	}
+
+	return h.max
+}
+
+// index is v itself below subCount. Above it, every doubling of the value
+// gets halfSub buckets, keyed by the top subBits bits of v.
+func index(v int64) int {
+	if v < subCount {
+		return int(v)
+	}
+
+	shift := bits.Len64(uint64(v)) - subBits
+	mantissa := int(v >> shift)
+
+	return subCount + (shift-1)*halfSub + mantissa - halfSub
+}
+
+// upper is the highest value that falls into bucket i.
+func upper(i int) int64 {
+	if i < subCount {
+		return int64(i)
+	}
+
+	shift := (i-subCount)/halfSub + 1
+	mantissa := int64((i-subCount)%halfSub + halfSub)
+
+	return (mantissa+1)<<shift - 1
+}
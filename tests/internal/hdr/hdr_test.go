@@ -0,0 +1,63 @@
+package hdr
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestIndexUpper(t *testing.T) {
+	for _, v := range []int64{0, 1, 255, 256, 257, 511, 512, 1000, 1 << 20, 123456789, math.MaxInt64} {
+		i := index(v)
+		if upper(i) < v {
+			t.Errorf("value %d above the upper bound %d of its bucket", v, upper(i))
+		}
+		if i > 0 && upper(i-1) >= v {
+			t.Errorf("value %d not above the upper bound %d of the bucket before", v, upper(i-1))
+		}
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	h := New()
+
+	values := make([]int64, 100_000)
+	for i := range values {
+		values[i] = int64(rng.ExpFloat64() * 1000)
+		h.Record(values[i])
+	}
+	slices.Sort(values)
+
+	for _, q := range []float64{0.5, 0.9, 0.99, 0.999} {
+		want := values[int(math.Ceil(q*float64(len(values))))-1]
+		got := h.Quantile(q)
+
+		if got < want || float64(got-want) > float64(want)/halfSub+1 {
+			t.Errorf("p%v = %d, want %d within %.1f%%", 100*q, got, want, 100.0/halfSub)
+		}
+	}
+
+	if h.Quantile(1) != values[len(values)-1] || h.Max() != values[len(values)-1] {
+		t.Errorf("max = %d, p100 = %d, want %d", h.Max(), h.Quantile(1), values[len(values)-1])
+	}
+	if h.Count() != uint64(len(values)) {
+		t.Errorf("count = %d, want %d", h.Count(), len(values))
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, b := New(), New()
+	a.Record(10)
+	b.RecordN(1_000_000, 3)
+
+	a.Merge(b)
+
+	if a.Count() != 4 || a.Min() != 10 || a.Max() != 1_000_000 {
+		t.Errorf("count %d, min %d, max %d", a.Count(), a.Min(), a.Max())
+	}
+	if got := a.Quantile(0.25); got != 10 {
+		t.Errorf("p25 = %d, want 10", got)
+	}
+}
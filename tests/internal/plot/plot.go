@@ -0,0 +1,278 @@
+package plot
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Point is one value of a series. Low and High are the ends of its error bar;
+// no bar is drawn unless Low < High.
+type Point struct {
+	X, Y      float64
+	Low, High float64
+}
+
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// Chart is a line chart with one line per series. Series named in Split are
+// drawn in a second panel below the first one; both panels share the X axis.
+// Colors follow the order of Series, so a series keeps its color whether it
+// is split off or not.
+type Chart struct {
+	Title  string
+	XLabel string
+	YLabel string
+	LogX   bool
+	LogY   bool
+	Split  []string
+	Series []Series
+	Width  int
+	Height int
+}
+
+const (
+	marginLeft   = 100.
+	marginRight  = 30.
+	marginTop    = 30.
+	marginBottom = 70.
+	panelGap     = 80.
+	fontSize     = 16
+	labelSize    = 18
+)
+
+type panel struct {
+	series  []int
+	x, y    float64
+	w, h    float64
+	yScale  scale
+	hasData bool
+}
+
+// WriteSVG renders the chart. Points that cannot be shown on a log axis are
+// left out.
+func (c Chart) WriteSVG(w io.Writer) error {
+	width, height := float64(cmp.Or(c.Width, 1000)), float64(cmp.Or(c.Height, 800))
+
+	var top, bottom []int
+	for i, s := range c.Series {
+		if slices.Contains(c.Split, s.Name) {
+			bottom = append(bottom, i)
+		} else {
+			top = append(top, i)
+		}
+	}
+
+	groups := [][]int{top}
+	if len(bottom) > 0 {
+		groups = append(groups, bottom)
+	}
+
+	plotHeight := height - marginTop - marginBottom - float64(len(groups)-1)*(panelGap)
+	if c.Title != "" {
+		plotHeight -= labelSize * 2
+	}
+	panelHeight := plotHeight / float64(len(groups))
+
+	var xs []float64
+	for _, s := range c.Series {
+		for _, p := range s.Points {
+			xs = append(xs, p.X)
+		}
+	}
+	xScale := newScale(xs, c.LogX)
+
+	panels := make([]panel, len(groups))
+	y := marginTop
+	if c.Title != "" {
+		y += labelSize * 2
+	}
+	for i, group := range groups {
+		var ys []float64
+		for _, si := range group {
+			for _, p := range c.Series[si].Points {
+				ys = append(ys, p.Y)
+				if p.Low < p.High {
+					ys = append(ys, p.Low, p.High)
+				}
+			}
+		}
+
+		panels[i] = panel{
+			series: group,
+			x:      marginLeft,
+			y:      y,
+			w:      width - marginLeft - marginRight,
+			h:      panelHeight,
+			yScale: newScale(ys, c.LogY),
+		}
+		y += panelHeight + panelGap
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g" font-family="sans-serif">`+"\n", width, height, width, height)
+	fmt.Fprintf(out, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+
+	if c.Title != "" {
+		text(out, width/2, marginTop+labelSize/2, labelSize, "middle", "", c.Title)
+	}
+
+	for _, p := range panels {
+		c.drawPanel(out, p, xScale)
+	}
+
+	fmt.Fprintln(out, "</svg>")
+
+	return out.Flush()
+}
+
+func (c Chart) drawPanel(out io.Writer, p panel, xScale scale) {
+	px := func(v float64) float64 { return p.x + xScale.position(v)*p.w }
+	py := func(v float64) float64 { return p.y + (1-p.yScale.position(v))*p.h }
+
+	// Grid and ticks.
+	for _, t := range xScale.ticks() {
+		x := px(t)
+		fmt.Fprintf(out, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#ddd"/>`+"\n", x, p.y, x, p.y+p.h)
+		tickLabel(out, x, p.y+p.h+fontSize+6, "middle", t, xScale.log)
+	}
+	for _, t := range p.yScale.ticks() {
+		y := py(t)
+		fmt.Fprintf(out, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#ddd"/>`+"\n", p.x, y, p.x+p.w, y)
+		tickLabel(out, p.x-8, y+fontSize/3, "end", t, p.yScale.log)
+	}
+
+	fmt.Fprintf(out, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="none" stroke="black"/>`+"\n", p.x, p.y, p.w, p.h)
+
+	text(out, p.x+p.w/2, p.y+p.h+fontSize+labelSize+16, labelSize, "middle", "", c.XLabel)
+	yMid := p.y + p.h/2
+	text(out, marginLeft-70, yMid, labelSize, "middle", fmt.Sprintf("rotate(-90 %.1f %.1f)", marginLeft-70, yMid), c.YLabel)
+
+	// Lines and error bars.
+	var drawn [][2]float64
+	for _, si := range p.series {
+		color := Color(si, len(c.Series))
+
+		var points []string
+		for _, pt := range c.Series[si].Points {
+			if !xScale.shows(pt.X) || !p.yScale.shows(pt.Y) {
+				continue
+			}
+
+			x, y := px(pt.X), py(pt.Y)
+			if len(points) > 0 {
+				prev := drawn[len(drawn)-1]
+				drawn = append(drawn, [2]float64{(prev[0] + x) / 2, (prev[1] + y) / 2})
+			}
+			drawn = append(drawn, [2]float64{x, y})
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+
+			if pt.Low < pt.High && p.yScale.shows(pt.Low) {
+				low, high := py(pt.Low), py(pt.High)
+				fmt.Fprintf(out, `<path d="M%.1f %.1fV%.1fM%.1f %.1fH%.1fM%.1f %.1fH%.1f" stroke="%s" stroke-width="1.5"/>`+"\n",
+					x, low, high, x-4, low, x+4, x-4, high, x+4, color)
+			}
+		}
+
+		if len(points) > 0 {
+			fmt.Fprintf(out, `<polyline points="%s" fill="none" stroke="%s" stroke-width="3" stroke-linejoin="round"/>`+"\n", strings.Join(points, " "), color)
+		}
+	}
+
+	if len(p.series) == 0 {
+		return
+	}
+
+	var longest int
+	for _, si := range p.series {
+		longest = max(longest, len([]rune(c.Series[si].Name)))
+	}
+
+	lineHeight := float64(fontSize) * 1.5
+	legendW, legendH := float64(longest)*fontSize*0.6+60, float64(len(p.series))*lineHeight+12
+	lx, ly := p.legendCorner(legendW, legendH, drawn)
+
+	fmt.Fprintf(out, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="white" fill-opacity="0.8" stroke="#bbb" rx="4"/>`+"\n", lx, ly, legendW, legendH)
+	for i, si := range p.series {
+		y := ly + 6 + lineHeight*(float64(i)+0.5)
+		fmt.Fprintf(out, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="3"/>`+"\n", lx+10, y, lx+40, y, Color(si, len(c.Series)))
+		text(out, lx+48, y+fontSize/3, fontSize, "start", "", c.Series[si].Name)
+	}
+}
+
+// legendCorner picks the corner of the panel whose legend box covers the
+// fewest drawn points, preferring the top left one on ties.
+func (p panel) legendCorner(w, h float64, drawn [][2]float64) (float64, float64) {
+	const inset = 12.
+
+	corners := [][2]float64{
+		{p.x + inset, p.y + inset},
+		{p.x + p.w - w - inset, p.y + inset},
+		{p.x + inset, p.y + p.h - h - inset},
+		{p.x + p.w - w - inset, p.y + p.h - h - inset},
+	}
+
+	best, bestCovered := corners[0], len(drawn)+1
+	for _, corner := range corners {
+		var covered int
+		for _, pt := range drawn {
+			if pt[0] >= corner[0] && pt[0] <= corner[0]+w && pt[1] >= corner[1] && pt[1] <= corner[1]+h {
+				covered++
+			}
+		}
+
+		if covered < bestCovered {
+			best, bestCovered = corner, covered
+		}
+	}
+
+	return best[0], best[1]
+}
+
+// Color spreads n colors evenly over the hue circle, like the husl palette
+// the Python plots used.
+func Color(i, n int) string {
+	hue := 10 + 360*float64(i)/float64(max(n, 1))
+	return fmt.Sprintf("hsl(%.0f,65%%,48%%)", math.Mod(hue, 360))
+}
+
+func text(out io.Writer, x, y float64, size int, anchor, transform, s string) {
+	if s == "" {
+		return
+	}
+
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(s))
+
+	if transform != "" {
+		transform = fmt.Sprintf(` transform="%s"`, transform)
+	}
+
+	fmt.Fprintf(out, `<text x="%.1f" y="%.1f" font-size="%d" text-anchor="%s"%s>%s</text>`+"\n", x, y, size, anchor, transform, escaped.String())
+}
+
+func tickLabel(out io.Writer, x, y float64, anchor string, v float64, log bool) {
+	if log {
+		fmt.Fprintf(out, `<text x="%.1f" y="%.1f" font-size="%d" text-anchor="%s">10<tspan dy="-7" font-size="%d">%d</tspan></text>`+"\n",
+			x, y, fontSize, anchor, fontSize-4, int(math.Round(math.Log10(v))))
+		return
+	}
+
+	text(out, x, y, fontSize, anchor, "", formatTick(v))
+}
+
+func formatTick(v float64) string {
+	// Ten significant digits drop the float noise of the tick arithmetic.
+	v, _ = strconv.ParseFloat(strconv.FormatFloat(v, 'g', 10, 64), 64)
+
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
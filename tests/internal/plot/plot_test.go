@@ -0,0 +1,84 @@
+package plot
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteSVG(t *testing.T) {
+	chart := Chart{
+		XLabel: "size",
+		YLabel: "ns & more",
+		LogX:   true,
+		Split:  []string{"Cuckoo"},
+		Series: []Series{
+			{Name: "Chain", Points: []Point{{X: 10, Y: 1}, {X: 100, Y: 2, Low: 1.5, High: 2.5}, {X: 0, Y: 3}}},
+			{Name: "Cuckoo", Points: []Point{{X: 10, Y: 100}, {X: 1000, Y: 300}}},
+		},
+	}
+
+	var svg bytes.Buffer
+	if err := chart.WriteSVG(&svg); err != nil {
+		t.Fatal(err)
+	}
+
+	elements := map[string]int{}
+	decoder := xml.NewDecoder(bytes.NewReader(svg.Bytes()))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, svg.String())
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			elements[start.Name.Local]++
+		}
+	}
+
+	if elements["polyline"] != 2 {
+		t.Errorf("got %d lines, want 2", elements["polyline"])
+	}
+	if elements["path"] != 1 {
+		t.Errorf("got %d error bars, want 1", elements["path"])
+	}
+	// Two panel frames and two legends.
+	if elements["rect"] != 1+2+2 {
+		t.Errorf("got %d rects, want 5", elements["rect"])
+	}
+
+	out := svg.String()
+	for _, want := range []string{"Chain", "Cuckoo", "ns &amp; more", ">10<tspan"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("SVG does not contain %q", want)
+		}
+	}
+}
+
+func TestTicks(t *testing.T) {
+	tests := []struct {
+		values []float64
+		log    bool
+		want   []string
+	}{
+		{[]float64{10, 10000}, true, []string{"10", "100", "1000", "10000"}},
+		{[]float64{0, 20000}, false, []string{"0", "5000", "10000", "15000", "20000"}},
+		{[]float64{0.1, 0.5}, false, []string{"0.1", "0.2", "0.3", "0.4", "0.5"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, tick := range newScale(tt.values, tt.log).ticks() {
+			got = append(got, formatTick(tick))
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ticks of %v (log %v): got %v, want %v", tt.values, tt.log, got, tt.want)
+		}
+	}
+}
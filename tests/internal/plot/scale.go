@@ -0,0 +1,86 @@
+package plot
+
+import (
+	"math"
+)
+
+// scale maps data values to [0, 1]. Log scales work on log10 of the values.
+type scale struct {
+	log      bool
+	min, max float64
+}
+
+func newScale(values []float64, log bool) scale {
+	s := scale{log: log, min: math.Inf(1), max: math.Inf(-1)}
+
+	for _, v := range values {
+		if !s.shows(v) {
+			continue
+		}
+		v = s.transform(v)
+		s.min, s.max = min(s.min, v), max(s.max, v)
+	}
+
+	if math.IsInf(s.min, 1) {
+		s.min, s.max = 0, 1
+	}
+
+	if s.min == s.max {
+		s.min, s.max = s.min-0.5, s.max+0.5
+	}
+
+	pad := (s.max - s.min) * 0.05
+	s.min, s.max = s.min-pad, s.max+pad
+
+	return s
+}
+
+func (s scale) shows(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0) && (!s.log || v > 0)
+}
+
+func (s scale) transform(v float64) float64 {
+	if s.log {
+		return math.Log10(v)
+	}
+
+	return v
+}
+
+func (s scale) position(v float64) float64 {
+	return (s.transform(v) - s.min) / (s.max - s.min)
+}
+
+// ticks are the powers of ten inside a log scale, or round steps of 1, 2 or
+// 5 times a power of ten inside a linear one.
+func (s scale) ticks() []float64 {
+	var ticks []float64
+
+	if s.log {
+		for e := math.Ceil(s.min); e <= s.max; e++ {
+			ticks = append(ticks, math.Pow(10, e))
+		}
+
+		return ticks
+	}
+
+	step := niceStep((s.max - s.min) / 6)
+	for t := math.Ceil(s.min/step) * step; t <= s.max; t += step {
+		// Rounding drops the accumulated error, so no tick reads -0.
+		ticks = append(ticks, math.Round(t/step)*step+0)
+	}
+
+	return ticks
+}
+
+func niceStep(raw float64) float64 {
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m*magnitude {
+			return m * magnitude
+		}
+	}
+
+	return 10 * magnitude
+}
@@ -0,0 +1,110 @@
+package results
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Record is one measured value with every dimension of the cell it came
+// from. Labels carry the dimensions that only some metrics have, e.g. the
+// chain length of a histogram bin.
+type Record struct {
+	Experiment string            `json:"experiment"`
+	Method     string            `json:"method"`
+	Hasher     string            `json:"hasher,omitempty"`
+	KeyKind    string            `json:"keyKind,omitempty"`
+	LoadFactor float64           `json:"loadFactor,omitempty"`
+	Size       int               `json:"size,omitempty"`
+	Metric     string            `json:"metric"`
+	Value      float64           `json:"value"`
+	Unit       string            `json:"unit,omitempty"`
+	Repetition int               `json:"repetition"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+type Sink interface {
+	Write(record Record) error
+	Close() error
+}
+
+// JSONL writes one JSON object per line.
+type JSONL struct {
+	closer  io.Closer
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func NewJSONL(path string) (*JSONL, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := NewJSONLWriter(file)
+	sink.closer = file
+
+	return sink, nil
+}
+
+// NewJSONLWriter writes to w. Close flushes, but leaves w open.
+func NewJSONLWriter(w io.Writer) *JSONL {
+	writer := bufio.NewWriter(w)
+
+	return &JSONL{writer: writer, encoder: json.NewEncoder(writer)}
+}
+
+func (s *JSONL) Write(record Record) error {
+	return s.encoder.Encode(record)
+}
+
+func (s *JSONL) Close() error {
+	if s.closer == nil {
+		return s.writer.Flush()
+	}
+
+	return errors.Join(s.writer.Flush(), s.closer.Close())
+}
+
+// Multi fans every record out to all of its sinks.
+type Multi []Sink
+
+func (m Multi) Write(record Record) error {
+	for _, sink := range m {
+		if err := sink.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m Multi) Close() error {
+	var errs []error
+	for _, sink := range m {
+		errs = append(errs, sink.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// ReadJSONL reads the records a JSONL sink wrote.
+func ReadJSONL(r io.Reader) ([]Record, error) {
+	var (
+		records []Record
+		decoder = json.NewDecoder(r)
+	)
+
+	for {
+		var record Record
+		if err := decoder.Decode(&record); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("record %d: %w", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+}
@@ -0,0 +1,53 @@
+package results
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+
+	sink, err := NewJSONL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []Record{
+		{Experiment: "Probes", Method: "Chain", Hasher: "Multiplicative", KeyKind: "RandomKey", LoadFactor: 0.5, Size: 5000, Metric: "probes", Value: 1.25, Unit: "probes/lookup"},
+		{Experiment: "Layout", Method: "Double", KeyKind: "SequentialKey", Metric: "clusters", Value: 3, Repetition: 1, Labels: map[string]string{"length": "2"}},
+	}
+
+	for _, record := range records {
+		if err = sink.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var got []Record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record Record
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %d: %v", len(got)+1, err)
+		}
+		got = append(got, record)
+	}
+
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("got %+v, want %+v", got, records)
+	}
+}
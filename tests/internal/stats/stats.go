@@ -0,0 +1,188 @@
+package stats
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+type Summary struct {
+	N      int
+	Mean   float64
+	StdDev float64
+	Min    float64
+	Max    float64
+	CILow  float64
+	CIHigh float64
+}
+
+// Summarize computes the sample statistics and the 95% confidence interval of
+// the mean, using Student's t distribution since repetitions are few.
+func Summarize(values []float64) Summary {
+	n := len(values)
+	if n == 0 {
+		return Summary{}
+	}
+
+	s := Summary{N: n, Min: slices.Min(values), Max: slices.Max(values)}
+
+	for _, v := range values {
+		s.Mean += v
+	}
+	s.Mean /= float64(n)
+
+	if n > 1 {
+		for _, v := range values {
+			s.StdDev += (v - s.Mean) * (v - s.Mean)
+		}
+		s.StdDev = math.Sqrt(s.StdDev / float64(n-1))
+	}
+
+	margin := 0.
+	if n > 1 {
+		margin = TCritical95(n-1) * s.StdDev / math.Sqrt(float64(n))
+	}
+	s.CILow, s.CIHigh = s.Mean-margin, s.Mean+margin
+
+	return s
+}
+
+var tTable = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// TCritical95 is the two-sided 95% critical value of Student's t distribution
+// with df degrees of freedom.
+func TCritical95(df int) float64 {
+	switch {
+	case df < 1:
+		return math.NaN()
+	case df <= len(tTable):
+		return tTable[df-1]
+	case df <= 60:
+		return 2.000
+	case df <= 120:
+		return 1.980
+	default:
+		return 1.960
+	}
+}
+
+// MannWhitneyU tests whether x and y come from the same distribution and
+// returns the U statistic of x with the two-sided p-value. Like benchstat it
+// uses the exact distribution of U for small samples without ties and the
+// tie-corrected normal approximation otherwise.
+func MannWhitneyU(x, y []float64) (u, p float64) {
+	n1, n2 := len(x), len(y)
+	if n1 == 0 || n2 == 0 {
+		return 0, 1
+	}
+
+	type sample struct {
+		value float64
+		fromX bool
+	}
+
+	all := make([]sample, 0, n1+n2)
+	for _, v := range x {
+		all = append(all, sample{v, true})
+	}
+	for _, v := range y {
+		all = append(all, sample{v, false})
+	}
+	slices.SortFunc(all, func(a, b sample) int { return cmp.Compare(a.value, b.value) })
+
+	// Rank sum of x with average ranks for ties, and the tie correction term.
+	var rankSum, ties float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromX {
+				rankSum += rank
+			}
+		}
+
+		if t := float64(j - i); t > 1 {
+			ties += t*t*t - t
+		}
+		i = j
+	}
+
+	u = rankSum - float64(n1*(n1+1))/2
+
+	if ties == 0 && n1 <= exactLimit && n2 <= exactLimit {
+		return u, exactP(n1, n2, u)
+	}
+
+	n, m := float64(n1), float64(n2)
+	mean := n * m / 2
+	variance := n * m / 12 * ((n + m + 1) - ties/((n+m)*(n+m-1)))
+	if variance <= 0 {
+		return u, 1
+	}
+
+	// Continuity correction towards the mean.
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	p = math.Erfc(max(z, 0) / math.Sqrt2)
+
+	return u, min(p, 1)
+}
+
+// exactLimit is the largest sample size the exact distribution of U is
+// computed for.
+const exactLimit = 50
+
+// exactP counts the orderings of n1 and n2 values that give a U at least as
+// extreme as u.
+func exactP(n1, n2 int, u float64) float64 {
+	// counts[i][j][k] is the number of orderings of i x-values and j
+	// y-values with U = k; only the layers of the current i are kept.
+	maxU := n1 * n2
+	prev := make([][]float64, n2+1)
+	for j := range prev {
+		prev[j] = make([]float64, maxU+1)
+		prev[j][0] = 1
+	}
+
+	for i := 1; i <= n1; i++ {
+		cur := make([][]float64, n2+1)
+		cur[0] = make([]float64, maxU+1)
+		cur[0][0] = 1
+
+		for j := 1; j <= n2; j++ {
+			cur[j] = make([]float64, maxU+1)
+			for k := 0; k <= i*j; k++ {
+				// The largest value is either an x, which beats all j
+				// y-values, or a y, which adds nothing to U.
+				if k >= j {
+					cur[j][k] += prev[j][k-j]
+				}
+				cur[j][k] += cur[j-1][k]
+			}
+		}
+
+		prev = cur
+	}
+
+	counts := prev[n2]
+
+	var total, lower, upper float64
+	for k, c := range counts {
+		total += c
+		if float64(k) <= u {
+			lower += c
+		}
+		if float64(k) >= u {
+			upper += c
+		}
+	}
+
+	return min(1, 2*min(lower, upper)/total)
+}
@@ -0,0 +1,73 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	s := Summarize([]float64{2, 4, 4, 4, 5, 5, 7, 9})
+
+	if s.N != 8 || s.Mean != 5 || s.Min != 2 || s.Max != 9 {
+		t.Errorf("unexpected summary: %+v", s)
+	}
+
+	wantStdDev := math.Sqrt(32. / 7)
+	if math.Abs(s.StdDev-wantStdDev) > 1e-9 {
+		t.Errorf("std dev: got %v, want %v", s.StdDev, wantStdDev)
+	}
+
+	margin := 2.365 * wantStdDev / math.Sqrt(8)
+	if math.Abs(s.CILow-(5-margin)) > 1e-9 || math.Abs(s.CIHigh-(5+margin)) > 1e-9 {
+		t.Errorf("confidence interval: got [%v, %v], want 5±%v", s.CILow, s.CIHigh, margin)
+	}
+}
+
+func TestSummarizeSingle(t *testing.T) {
+	s := Summarize([]float64{3})
+
+	if s.Mean != 3 || s.StdDev != 0 || s.CILow != 3 || s.CIHigh != 3 {
+		t.Errorf("unexpected summary of a single value: %+v", s)
+	}
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	tests := []struct {
+		x, y  []float64
+		wantU float64
+		wantP float64
+	}{
+		// Complete separation of 5 and 5 values: 2 of the 252 orderings.
+		{[]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}, 0, 2. / 252},
+		{[]float64{6, 7, 8, 9, 10}, []float64{1, 2, 3, 4, 5}, 25, 2. / 252},
+		// Interleaved: P(U <= 3) is 7 of the 20 orderings.
+		{[]float64{1, 3, 5}, []float64{2, 4, 6}, 3, 0.7},
+		{[]float64{1}, []float64{2}, 0, 1},
+		// Ties fall back to the normal approximation.
+		{[]float64{5, 5, 5}, []float64{5, 5, 5}, 4.5, 1},
+	}
+
+	for _, tt := range tests {
+		u, p := MannWhitneyU(tt.x, tt.y)
+		if u != tt.wantU || math.Abs(p-tt.wantP) > 1e-9 {
+			t.Errorf("MannWhitneyU(%v, %v) = %v, %v, want %v, %v", tt.x, tt.y, u, p, tt.wantU, tt.wantP)
+		}
+	}
+}
+
+func TestMannWhitneyUNormal(t *testing.T) {
+	x := make([]float64, 60)
+	y := make([]float64, 60)
+	for i := range x {
+		x[i] = float64(i % 10)
+		y[i] = float64(i%10) + 3
+	}
+
+	if _, p := MannWhitneyU(x, y); p > 0.001 {
+		t.Errorf("shifted samples: p = %v, want a significant difference", p)
+	}
+
+	if _, p := MannWhitneyU(x, x); p < 0.99 {
+		t.Errorf("identical samples: p = %v, want about 1", p)
+	}
+}
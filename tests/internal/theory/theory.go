@@ -0,0 +1,79 @@
+package theory
+
+import "math"
+
+// Model gives the expected number of probes of a lookup as a function of the
+// table load alpha = Size()/Capacity(). The formulas are the classical ones
+// from Knuth, TAOCP vol. 3, 6.4, adjusted to the way the tables in this repo
+// count probes: every inspected slot or chain element is one probe.
+type Model struct {
+	Name         string
+	Successful   func(alpha float64) float64
+	Unsuccessful func(alpha float64) float64
+}
+
+var (
+	// Chaining scans on average half of the chain to find a key and the whole
+	// chain to miss one. Hopscotch walks the neighbourhood bitmap of the home
+	// bucket, which holds exactly the keys of the "chain", so it follows the
+	// same model.
+	Chaining = Model{
+		Name:         "Chaining",
+		Successful:   func(alpha float64) float64 { return 1 + alpha/2 },
+		Unsuccessful: func(alpha float64) float64 { return alpha },
+	}
+
+	LinearProbing = Model{
+		Name:         "LinearProbing",
+		Successful:   func(alpha float64) float64 { return (1 + 1/(1-alpha)) / 2 },
+		Unsuccessful: func(alpha float64) float64 { return (1 + 1/((1-alpha)*(1-alpha))) / 2 },
+	}
+
+	// UniformHashing is the ideal double hashing scheme, where every probe
+	// sequence is a random permutation of the slots.
+	UniformHashing = Model{
+		Name: "UniformHashing",
+		Successful: func(alpha float64) float64 {
+			if alpha == 0 {
+				return 1
+			}
+			return math.Log(1/(1-alpha)) / alpha
+		},
+		Unsuccessful: func(alpha float64) float64 { return 1 / (1 - alpha) },
+	}
+
+	// RobinHood only reorders the keys of a linear probing table, so the mean
+	// displacement and the successful search cost are those of linear probing.
+	// An unsuccessful search stops as soon as it passes a key closer to its
+	// home than the search itself, which keeps it close to the successful
+	// cost; the same expression is used as an approximation.
+	RobinHood = Model{
+		Name:         "RobinHood",
+		Successful:   LinearProbing.Successful,
+		Unsuccessful: LinearProbing.Successful,
+	}
+
+	// Cuckoo looks at one slot per table. Alpha is the load of a single table
+	// (Capacity() is the size of one of them). A key stays in the first table
+	// unless its slot is taken, and n keys thrown into m slots of the first
+	// table occupy m(1 - e^(-n/m)) of them, which gives the share of keys
+	// found with one probe.
+	Cuckoo = Model{
+		Name: "Cuckoo",
+		Successful: func(alpha float64) float64 {
+			if alpha == 0 {
+				return 1
+			}
+			return 2 - (1-math.Exp(-alpha))/alpha
+		},
+		Unsuccessful: func(alpha float64) float64 { return 2 },
+	}
+)
+
+func RelativeError(measured, predicted float64) float64 {
+	if predicted == 0 {
+		return 0
+	}
+
+	return (measured - predicted) / predicted
+}
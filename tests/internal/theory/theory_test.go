@@ -0,0 +1,33 @@
+package theory
+
+import (
+	"math"
+	"testing"
+)
+
+func TestModels(t *testing.T) {
+	tests := []struct {
+		model        Model
+		alpha        float64
+		successful   float64
+		unsuccessful float64
+	}{
+		{Chaining, 0.5, 1.25, 0.5},
+		{LinearProbing, 0.5, 1.5, 2.5},
+		{LinearProbing, 0.9, 5.5, 50.5},
+		{UniformHashing, 0.5, 2 * math.Ln2, 2},
+		{UniformHashing, 0, 1, 1},
+		{RobinHood, 0.5, 1.5, 1.5},
+		{Cuckoo, 0, 1, 2},
+		{Cuckoo, 1, 1 + 1/math.E, 2},
+	}
+
+	for _, tt := range tests {
+		if got := tt.model.Successful(tt.alpha); math.Abs(got-tt.successful) > 1e-9 {
+			t.Errorf("%s successful at %.2f: got %v, want %v", tt.model.Name, tt.alpha, got, tt.successful)
+		}
+		if got := tt.model.Unsuccessful(tt.alpha); math.Abs(got-tt.unsuccessful) > 1e-9 {
+			t.Errorf("%s unsuccessful at %.2f: got %v, want %v", tt.model.Name, tt.alpha, got, tt.unsuccessful)
+		}
+	}
+}
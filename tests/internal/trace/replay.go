@@ -0,0 +1,98 @@
+package trace
+
+import (
+	"analyze/internal/hash_table"
+)
+
+// Recorder is a HashTable that writes every Insert, Get and Delete to a
+// trace before passing it on. Write errors do not stop the table; the first
+// one is kept for Err.
+type Recorder struct {
+	hash_table.HashTable
+	w   *Writer
+	err error
+}
+
+func NewRecorder(ht hash_table.HashTable, w *Writer) *Recorder {
+	return &Recorder{HashTable: ht, w: w}
+}
+
+func (r *Recorder) Insert(key int, value any) {
+	r.record(Event{Op: Insert, Key: key, ValueSize: valueSize(value)})
+	r.HashTable.Insert(key, value)
+}
+
+func (r *Recorder) Get(key int) (any, bool) {
+	r.record(Event{Op: Get, Key: key})
+	return r.HashTable.Get(key)
+}
+
+func (r *Recorder) Delete(key int) {
+	r.record(Event{Op: Delete, Key: key})
+	r.HashTable.Delete(key)
+}
+
+func (r *Recorder) Err() error {
+	return r.err
+}
+
+func (r *Recorder) record(e Event) {
+	if r.err == nil {
+		r.err = r.w.Write(e)
+	}
+}
+
+// valueSize knows the size of the values that are plain bytes.
+func valueSize(value any) int {
+	switch v := value.(type) {
+	case []byte:
+		return len(v)
+	case string:
+		return len(v)
+	default:
+		return 0
+	}
+}
+
+// Stats counts what a replay did. Hits and Misses are those of the gets.
+type Stats struct {
+	Operations int
+	Hits       int
+	Misses     int
+}
+
+// Replay applies the events to ht in order. Inserts without a value size
+// store the key; the others store a slice of that many bytes cut from one
+// shared buffer, so the replay itself does not allocate values.
+func Replay(ht hash_table.HashTable, events []Event) Stats {
+	var (
+		stats Stats
+		buf   []byte
+	)
+
+	for _, e := range events {
+		switch e.Op {
+		case Insert:
+			if e.ValueSize == 0 {
+				ht.Insert(e.Key, e.Key)
+				break
+			}
+			if e.ValueSize > len(buf) {
+				buf = make([]byte, e.ValueSize)
+			}
+			ht.Insert(e.Key, buf[:e.ValueSize])
+		case Get:
+			if _, ok := ht.Get(e.Key); ok {
+				stats.Hits++
+			} else {
+				stats.Misses++
+			}
+		case Delete:
+			ht.Delete(e.Key)
+		}
+
+		stats.Operations++
+	}
+
+	return stats
+}
@@ -0,0 +1,170 @@
+// Package trace records the operations a hash table receives and replays
+// them against another table, so every method can be measured on exactly
+// the same access pattern.
+//
+// A trace is the magic "HTTRACE" and a version byte, followed by one record
+// per operation: the op byte, the key as a zigzag varint and, when the op
+// byte has hasValueSize set, the size of the stored value as a uvarint.
+package trace
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type Op uint8
+
+const (
+	Insert Op = iota + 1
+	Get
+	Delete
+)
+
+func (op Op) String() string {
+	switch op {
+	case Insert:
+		return "insert"
+	case Get:
+		return "get"
+	case Delete:
+		return "delete"
+	default:
+		return fmt.Sprintf("op(%d)", uint8(op))
+	}
+}
+
+const (
+	magic   = "HTTRACE"
+	version = 1
+
+	hasValueSize = 0x80
+)
+
+// Event is one operation. ValueSize is the size in bytes of an inserted
+// value when the recorder could tell it, zero otherwise.
+type Event struct {
+	Op        Op
+	Key       int
+	ValueSize int
+}
+
+type Writer struct {
+	w   *bufio.Writer
+	buf [1 + 2*binary.MaxVarintLen64]byte
+}
+
+// NewWriter writes the header of a trace to w. Events are buffered until
+// Flush.
+func NewWriter(w io.Writer) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(magic); err != nil {
+		return nil, err
+	}
+	if err := bw.WriteByte(version); err != nil {
+		return nil, err
+	}
+
+	return &Writer{w: bw}, nil
+}
+
+func (w *Writer) Write(e Event) error {
+	if e.Op < Insert || e.Op > Delete {
+		return fmt.Errorf("trace: invalid %v", e.Op)
+	}
+	if e.ValueSize < 0 {
+		return fmt.Errorf("trace: negative value size %d", e.ValueSize)
+	}
+
+	w.buf[0] = byte(e.Op)
+	n := 1 + binary.PutVarint(w.buf[1:], int64(e.Key))
+	if e.ValueSize > 0 {
+		w.buf[0] |= hasValueSize
+		n += binary.PutUvarint(w.buf[n:], uint64(e.ValueSize))
+	}
+
+	_, err := w.w.Write(w.buf[:n])
+	return err
+}
+
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader checks the header of the trace in r.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("trace: reading header: %w", err)
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, errors.New("trace: not a trace file")
+	}
+	if header[len(magic)] != version {
+		return nil, fmt.Errorf("trace: unsupported version %d", header[len(magic)])
+	}
+
+	return &Reader{r: br}, nil
+}
+
+// Next returns the next event, or io.EOF after the last one.
+func (r *Reader) Next() (Event, error) {
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return Event{}, err
+	}
+
+	e := Event{Op: Op(b &^ hasValueSize)}
+	if e.Op < Insert || e.Op > Delete {
+		return Event{}, fmt.Errorf("trace: invalid %v", e.Op)
+	}
+
+	key, err := binary.ReadVarint(r.r)
+	if err != nil {
+		return Event{}, truncated(err)
+	}
+	e.Key = int(key)
+
+	if b&hasValueSize != 0 {
+		size, err := binary.ReadUvarint(r.r)
+		if err != nil {
+			return Event{}, truncated(err)
+		}
+		e.ValueSize = int(size)
+	}
+
+	return e, nil
+}
+
+// ReadAll reads the rest of the trace into memory.
+func (r *Reader) ReadAll() ([]Event, error) {
+	var events []Event
+
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+
+		events = append(events, e)
+	}
+}
+
+func truncated(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return fmt.Errorf("trace: %w", err)
+}
@@ -0,0 +1,84 @@
+package trace
+
+import (
+	"analyze/internal/hash_table/chain"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := NewRecorder(chain.New(8, chain.WithSeed(1)), w)
+	recorder.Insert(1, 1)
+	recorder.Insert(-300, []byte("value"))
+	recorder.Get(1)
+	recorder.Get(2)
+	recorder.Delete(1)
+	recorder.Get(1)
+
+	if err = recorder.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Event{
+		{Op: Insert, Key: 1},
+		{Op: Insert, Key: -300, ValueSize: 5},
+		{Op: Get, Key: 1},
+		{Op: Get, Key: 2},
+		{Op: Delete, Key: 1},
+		{Op: Get, Key: 1},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+
+	ht := chain.New(8, chain.WithSeed(2))
+	stats := Replay(ht, events)
+
+	if stats != (Stats{Operations: 6, Hits: 1, Misses: 2}) {
+		t.Errorf("stats = %+v", stats)
+	}
+	if value, ok := ht.Get(-300); !ok || len(value.([]byte)) != 5 {
+		t.Errorf("Get(-300) = %v, %v, want 5 bytes", value, ok)
+	}
+	if ht.Size() != 1 {
+		t.Errorf("size = %d, want 1", ht.Size())
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	if _, err := NewReader(bytes.NewReader([]byte("NOTATRACE"))); err == nil {
+		t.Error("accepted a file without the magic")
+	}
+
+	truncated := append([]byte(magic), version, byte(Insert)|hasValueSize, 2)
+	r, err := NewReader(bytes.NewReader(truncated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("err = %v, want unexpected EOF", err)
+	}
+}
@@ -0,0 +1,57 @@
+package workload
+
+import "math/rand"
+
+// Distribution picks the record an operation works on. The number of
+// records changes while a workload runs, so a chooser is asked with the
+// current count n and returns an index in [0, n) into the records in
+// insertion order.
+type Distribution string
+
+const (
+	Uniform Distribution = "uniform"
+	// Zipfian makes a few records popular. Popularity is scattered over
+	// the records by hashing the rank, as YCSB's scrambled zipfian does.
+	Zipfian Distribution = "zipfian"
+	// Latest favours the records inserted last.
+	Latest Distribution = "latest"
+)
+
+var Distributions = []Distribution{Uniform, Zipfian, Latest}
+
+// DefaultZipfSkew is used when Options.ZipfSkew is not above 1, which
+// math/rand's Zipf requires.
+const DefaultZipfSkew = 1.1
+
+// zipfItems bounds the ranks the Zipf generator draws from; a rank above the
+// record count is folded back into it.
+const zipfItems = 1 << 40
+
+func (d Distribution) chooser(rng *rand.Rand, skew float64) func(n int) int {
+	if skew <= 1 {
+		skew = DefaultZipfSkew
+	}
+
+	switch d {
+	case Zipfian:
+		zipf := rand.NewZipf(rng, skew, 1, zipfItems)
+		return func(n int) int { return int(scramble(zipf.Uint64()) % uint64(n)) }
+	case Latest:
+		zipf := rand.NewZipf(rng, skew, 1, zipfItems)
+		return func(n int) int { return n - 1 - int(zipf.Uint64()%uint64(n)) }
+	default:
+		return func(n int) int { return rng.Intn(n) }
+	}
+}
+
+// scramble is the 64-bit FNV-1a hash of the rank.
+func scramble(rank uint64) uint64 {
+	h := uint64(14695981039346656037)
+	for range 8 {
+		h ^= rank & 0xff
+		h *= 1099511628211
+		rank >>= 8
+	}
+
+	return h
+}
@@ -0,0 +1,311 @@
+// Package workload drives a hash table with a mix of operations, in the
+// manner of the YCSB core workloads, and measures throughput and the latency
+// of every kind of operation.
+package workload
+
+import (
+	"analyze/internal/hash_table"
+	"analyze/internal/hdr"
+	"errors"
+	"fmt"
+	"iter"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+type Op int
+
+const (
+	Read Op = iota
+	Update
+	Insert
+	Scan
+	ReadModifyWrite
+	Delete
+)
+
+var opNames = []string{"read", "update", "insert", "scan", "rmw", "delete"}
+
+func (op Op) String() string {
+	return opNames[op]
+}
+
+// Mix holds the proportions of the operations. They need not sum to one.
+type Mix struct {
+	Read            float64
+	Update          float64
+	Insert          float64
+	Scan            float64
+	ReadModifyWrite float64
+	Delete          float64
+}
+
+func (m Mix) weights() []float64 {
+	return []float64{m.Read, m.Update, m.Insert, m.Scan, m.ReadModifyWrite, m.Delete}
+}
+
+// Workload is an operation mix and the distribution of the records it works
+// on. Scans read up to MaxScan records that follow each other in insertion
+// order, as a hash table has no other order.
+type Workload struct {
+	Name         string
+	Mix          Mix
+	Distribution Distribution
+	MaxScan      int
+}
+
+// Presets mirror the YCSB core workloads A-F; Churn adds deletes, which YCSB
+// does not have.
+var Presets = []Workload{
+	{Name: "A", Mix: Mix{Read: 0.5, Update: 0.5}, Distribution: Zipfian},
+	{Name: "B", Mix: Mix{Read: 0.95, Update: 0.05}, Distribution: Zipfian},
+	{Name: "C", Mix: Mix{Read: 1}, Distribution: Zipfian},
+	{Name: "D", Mix: Mix{Read: 0.95, Insert: 0.05}, Distribution: Latest},
+	{Name: "E", Mix: Mix{Scan: 0.95, Insert: 0.05}, Distribution: Zipfian, MaxScan: 100},
+	{Name: "F", Mix: Mix{Read: 0.5, ReadModifyWrite: 0.5}, Distribution: Zipfian},
+	{Name: "Churn", Mix: Mix{Read: 0.5, Insert: 0.25, Delete: 0.25}, Distribution: Uniform},
+}
+
+func Preset(name string) (Workload, bool) {
+	i := slices.IndexFunc(Presets, func(w Workload) bool { return w.Name == name })
+	if i < 0 {
+		return Workload{}, false
+	}
+
+	return Presets[i], true
+}
+
+func PresetNames() []string {
+	names := make([]string, len(Presets))
+	for i, w := range Presets {
+		names[i] = w.Name
+	}
+
+	return names
+}
+
+func (w Workload) Validate() error {
+	var sum float64
+	for _, weight := range w.Mix.weights() {
+		if weight < 0 {
+			return fmt.Errorf("workload %s: negative proportion", w.Name)
+		}
+		sum += weight
+	}
+
+	switch {
+	case sum == 0:
+		return fmt.Errorf("workload %s: no operations", w.Name)
+	case w.Mix.Scan > 0 && w.MaxScan < 1:
+		return fmt.Errorf("workload %s: scans need a positive MaxScan", w.Name)
+	case !slices.Contains(Distributions, w.Distribution):
+		return fmt.Errorf("workload %s: unknown distribution %q", w.Name, w.Distribution)
+	}
+
+	return nil
+}
+
+// Options decide how long a workload runs and where its keys come from.
+// Keys is pulled for the records of the load phase and for every insert; a
+// run stops early when it runs dry. At least one of Operations and Duration
+// must be set, the run stops at whichever comes first.
+type Options struct {
+	Records    int
+	Operations int
+	Duration   time.Duration
+	ZipfSkew   float64
+	Rand       *rand.Rand
+	Keys       iter.Seq[int]
+}
+
+// Latency summarises the latencies of one kind of operation.
+type Latency struct {
+	Op    Op
+	Count int
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	P999  time.Duration
+	Max   time.Duration
+}
+
+type Result struct {
+	Operations int
+	Elapsed    time.Duration
+	Latencies  []Latency
+}
+
+// Throughput is in operations per second.
+func (r Result) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(r.Operations) / r.Elapsed.Seconds()
+}
+
+// Run loads opts.Records records into ht and then runs the operations of w
+// against them. Every operation is timed on its own, so the clock reads are
+// part of the throughput.
+func Run(ht hash_table.HashTable, w Workload, opts Options) (Result, error) {
+	if err := w.Validate(); err != nil {
+		return Result{}, err
+	}
+	if opts.Operations <= 0 && opts.Duration <= 0 {
+		return Result{}, errors.New("workload: neither operations nor duration is set")
+	}
+	if opts.Rand == nil || opts.Keys == nil {
+		return Result{}, errors.New("workload: Rand and Keys are required")
+	}
+
+	nextKey, stop := iter.Pull(opts.Keys)
+	defer stop()
+
+	r := &runner{ht: ht, rng: opts.Rand, nextKey: nextKey}
+	for range opts.Records {
+		if !r.insert() {
+			break
+		}
+	}
+	if len(r.live) == 0 {
+		return Result{}, errors.New("workload: no records were loaded")
+	}
+
+	choose := w.Distribution.chooser(opts.Rand, opts.ZipfSkew)
+	ops := newOpChooser(opts.Rand, w.Mix)
+	latencies := make([]*hdr.Histogram, len(opNames))
+	for op := range latencies {
+		latencies[op] = hdr.New()
+	}
+
+	var (
+		done     int
+		start    = time.Now()
+		deadline = start.Add(opts.Duration)
+	)
+
+	for opts.Operations <= 0 || done < opts.Operations {
+		// Checking the clock on every operation would double its cost.
+		if opts.Duration > 0 && done%1024 == 0 && time.Now().After(deadline) {
+			break
+		}
+
+		op := ops()
+		if op == Delete && len(r.live) == 1 {
+			op = Read
+		}
+
+		var ok bool
+		opStart := time.Now()
+
+		switch op {
+		case Read:
+			ht.Get(r.live[choose(len(r.live))])
+			ok = true
+		case Update:
+			key := r.live[choose(len(r.live))]
+			ht.Insert(key, key)
+			ok = true
+		case Insert:
+			ok = r.insert()
+		case Scan:
+			first := choose(len(r.live))
+			for _, key := range r.live[first:min(first+1+r.rng.Intn(w.MaxScan), len(r.live))] {
+				ht.Get(key)
+			}
+			ok = true
+		case ReadModifyWrite:
+			key := r.live[choose(len(r.live))]
+			value, _ := ht.Get(key)
+			ht.Insert(key, value)
+			ok = true
+		case Delete:
+			r.delete(choose(len(r.live)))
+			ok = true
+		}
+
+		latency := time.Since(opStart)
+		if !ok {
+			break
+		}
+
+		latencies[op].Record(int64(latency))
+		done++
+	}
+
+	result := Result{Operations: done, Elapsed: time.Since(start)}
+	for op, h := range latencies {
+		if h.Count() > 0 {
+			result.Latencies = append(result.Latencies, summarize(Op(op), h))
+		}
+	}
+
+	return result, nil
+}
+
+type runner struct {
+	ht      hash_table.HashTable
+	rng     *rand.Rand
+	nextKey func() (int, bool)
+	// live holds the keys in the table in insertion order, apart from the
+	// holes deletes fill with the last key.
+	live []int
+}
+
+func (r *runner) insert() bool {
+	key, ok := r.nextKey()
+	if !ok {
+		return false
+	}
+
+	r.ht.Insert(key, key)
+	r.live = append(r.live, key)
+
+	return true
+}
+
+func (r *runner) delete(i int) {
+	r.ht.Delete(r.live[i])
+
+	last := len(r.live) - 1
+	r.live[i] = r.live[last]
+	r.live = r.live[:last]
+}
+
+func newOpChooser(rng *rand.Rand, mix Mix) func() Op {
+	weights := mix.weights()
+
+	cumulative := make([]float64, len(weights))
+	var sum float64
+	for i, weight := range weights {
+		sum += weight
+		cumulative[i] = sum
+	}
+
+	return func() Op {
+		x := rng.Float64() * sum
+		i, _ := slices.BinarySearch(cumulative, x)
+
+		// Operations of zero weight share their bound with the one before.
+		for weights[i] == 0 {
+			i++
+		}
+
+		return Op(i)
+	}
+}
+
+func summarize(op Op, h *hdr.Histogram) Latency {
+	return Latency{
+		Op:    op,
+		Count: int(h.Count()),
+		Mean:  time.Duration(h.Mean()),
+		P50:   time.Duration(h.Quantile(0.5)),
+		P90:   time.Duration(h.Quantile(0.9)),
+		P99:   time.Duration(h.Quantile(0.99)),
+		P999:  time.Duration(h.Quantile(0.999)),
+		Max:   time.Duration(h.Max()),
+	}
+}
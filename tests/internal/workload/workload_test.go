@@ -0,0 +1,78 @@
+package workload
+
+import (
+	"analyze/internal/hash_table/chain"
+	"math/rand"
+	"testing"
+)
+
+func sequentialKeys(yield func(int) bool) {
+	for i := 0; ; i++ {
+		if !yield(i) {
+			return
+		}
+	}
+}
+
+func TestRunPresets(t *testing.T) {
+	for _, w := range Presets {
+		t.Run(w.Name, func(t *testing.T) {
+			ht := chain.New(8, chain.WithSeed(1))
+
+			result, err := Run(ht, w, Options{Records: 1000, Operations: 5000, Rand: rand.New(rand.NewSource(1)), Keys: sequentialKeys})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Operations != 5000 {
+				t.Errorf("ran %d operations, want 5000", result.Operations)
+			}
+
+			counts := map[Op]int{}
+			var total int
+			for _, latency := range result.Latencies {
+				counts[latency.Op] = latency.Count
+				total += latency.Count
+
+				if latency.P50 > latency.P99 || latency.P99 > latency.Max {
+					t.Errorf("%v: quantiles out of order: %+v", latency.Op, latency)
+				}
+			}
+
+			if total != result.Operations {
+				t.Errorf("latencies cover %d operations, want %d", total, result.Operations)
+			}
+
+			if got, want := ht.Size(), 1000+counts[Insert]-counts[Delete]; got != want {
+				t.Errorf("table holds %d records, want %d", got, want)
+			}
+
+			for op, weight := range w.Mix.weights() {
+				if weight == 0 && counts[Op(op)] > 0 {
+					t.Errorf("%v ran %d times with zero weight", Op(op), counts[Op(op)])
+				}
+			}
+		})
+	}
+}
+
+func TestRunStopsWhenKeysRunOut(t *testing.T) {
+	keys := func(yield func(int) bool) {
+		for i := range 150 {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	w := Workload{Name: "inserts", Mix: Mix{Insert: 1}, Distribution: Uniform}
+
+	result, err := Run(chain.New(8), w, Options{Records: 100, Operations: 1000, Rand: rand.New(rand.NewSource(1)), Keys: keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Operations != 50 {
+		t.Errorf("ran %d inserts, want 50", result.Operations)
+	}
+}